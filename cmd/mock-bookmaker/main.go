@@ -0,0 +1,237 @@
+// Command mock-bookmaker serves fake versions of every supported bookmaker's
+// pages and JSON APIs, so the scraper can be run end to end with no internet.
+// Point the scraper at it with SITE_BASE_URLS=*=http://localhost:9090.
+//
+// Prices move every tick, markets are suspended now and then, and responses
+// can be slowed down, rate limited, blocked with 403s or captchas, or switched
+// to a changed layout, from flags at startup or at runtime through /_control.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page layouts. v2 renames the fields and classes the scrapers read, the way
+// a site redesign would.
+const (
+	layoutV1 = "v1"
+	layoutV2 = "v2"
+)
+
+// Settings control how the mock sites misbehave
+type Settings struct {
+	LatencyMS          int     `json:"latency_ms"`
+	SlowRate           float64 `json:"slow_rate"`
+	SlowDelayMS        int     `json:"slow_delay_ms"`
+	RateLimitRate      float64 `json:"rate_limit_rate"`
+	ForbiddenRate      float64 `json:"forbidden_rate"`
+	CaptchaRate        float64 `json:"captcha_rate"`
+	SuspendEvery       int     `json:"suspend_every"`
+	Layout             string  `json:"layout"`
+	LayoutEverySeconds int     `json:"layout_every_seconds"`
+}
+
+type mock struct {
+	market *market
+
+	mutex    sync.RWMutex
+	settings Settings
+}
+
+func main() {
+	addr := flag.String("addr", ":9090", "listen address")
+	count := flag.Int("fixtures", 12, "number of fixtures offered")
+	seed := flag.Int64("seed", 1, "seed for the generated fixtures")
+	tick := flag.Duration("tick", 30*time.Second, "how often prices move")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	slowRate := flag.Float64("slow-rate", 0, "fraction of responses delayed by -slow-delay")
+	slowDelay := flag.Duration("slow-delay", 10*time.Second, "delay of slow responses")
+	rateLimit := flag.Float64("rate-limit", 0, "fraction of requests answered with 429")
+	forbidden := flag.Float64("forbidden", 0, "fraction of requests answered with 403")
+	captcha := flag.Float64("captcha", 0, "fraction of requests answered with a captcha page")
+	suspendEvery := flag.Int("suspend-every", 5, "suspend each fixture's markets one tick in this many (0 never)")
+	layout := flag.String("layout", layoutV1, "page layout, v1 or v2")
+	layoutEvery := flag.Duration("layout-every", 0, "alternate between layouts this often (0 never)")
+	crawlDelay := flag.Float64("crawl-delay", 0, "Crawl-delay in seconds advertised in robots.txt (0 none)")
+	flag.Parse()
+
+	m := &mock{
+		market: newMarket(*count, *seed, *tick, time.Now()),
+		settings: Settings{
+			LatencyMS:          int(*latency / time.Millisecond),
+			SlowRate:           *slowRate,
+			SlowDelayMS:        int(*slowDelay / time.Millisecond),
+			RateLimitRate:      *rateLimit,
+			ForbiddenRate:      *forbidden,
+			CaptchaRate:        *captcha,
+			SuspendEvery:       *suspendEvery,
+			Layout:             *layout,
+			LayoutEverySeconds: int(*layoutEvery / time.Second),
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.Default()
+	router.GET("/", m.index)
+	router.GET("/robots.txt", robots(*crawlDelay))
+	router.GET("/_control", m.getSettings)
+	router.PUT("/_control", m.updateSettings)
+
+	sites := router.Group("/", m.chaos)
+	m.betikaRoutes(sites.Group("/betika"))
+	m.sportpesaRoutes(sites.Group("/sportpesa"))
+	m.mozzartbetRoutes(sites.Group("/mozzartbet"))
+	m.bet22Routes(sites.Group("/22bet"))
+
+	log.Printf("Mock bookmaker serving %d fixtures on %s", *count, *addr)
+	if err := router.Run(*addr); err != nil {
+		log.Fatal("Failed to start mock bookmaker:", err)
+	}
+}
+
+// index lists the mock sites and their fixtures
+func (m *mock) index(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"sites":    []string{"betika", "sportpesa", "mozzartbet", "22bet"},
+		"fixtures": m.market.upcoming(time.Now()),
+		"settings": m.current(),
+		"usage":    "SITE_BASE_URLS=*=http://" + c.Request.Host,
+	})
+}
+
+func (m *mock) getSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, m.current())
+}
+
+// updateSettings changes the settings given in the body and keeps the rest
+func (m *mock) updateSettings(c *gin.Context) {
+	m.mutex.Lock()
+	settings := m.settings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		m.mutex.Unlock()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if settings.Layout != layoutV1 && settings.Layout != layoutV2 {
+		m.mutex.Unlock()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "layout must be v1 or v2"})
+		return
+	}
+	m.settings = settings
+	m.mutex.Unlock()
+
+	log.Printf("Settings changed: %+v", settings)
+	c.JSON(http.StatusOK, settings)
+}
+
+func (m *mock) current() Settings {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.settings
+}
+
+// layout is the layout in force now
+func (m *mock) layout(now time.Time) string {
+	settings := m.current()
+	if settings.LayoutEverySeconds <= 0 {
+		return settings.Layout
+	}
+	period := time.Duration(settings.LayoutEverySeconds) * time.Second
+	if int(now.Sub(m.market.start)/period)%2 == 1 {
+		if settings.Layout == layoutV1 {
+			return layoutV2
+		}
+		return layoutV1
+	}
+	return settings.Layout
+}
+
+// robots serves a robots.txt that keeps crawlers off the control endpoint and
+// optionally asks them to slow down
+func robots(crawlDelay float64) gin.HandlerFunc {
+	body := "User-agent: *\nDisallow: /_control\n"
+	if crawlDelay > 0 {
+		body += fmt.Sprintf("Crawl-delay: %g\n", crawlDelay)
+	}
+	return func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	}
+}
+
+// chaos rate limits, blocks and slows down site requests as configured
+func (m *mock) chaos(c *gin.Context) {
+	settings := m.current()
+
+	if rand.Float64() < settings.CaptchaRate {
+		c.Data(http.StatusForbidden, "text/html; charset=utf-8", []byte(captchaPage))
+		c.Abort()
+		return
+	}
+	if rand.Float64() < settings.ForbiddenRate {
+		c.Data(http.StatusForbidden, "text/html; charset=utf-8", []byte(forbiddenPage))
+		c.Abort()
+		return
+	}
+	if rand.Float64() < settings.RateLimitRate {
+		c.Header("Retry-After", "5")
+		c.String(http.StatusTooManyRequests, "Too Many Requests")
+		c.Abort()
+		return
+	}
+
+	delay := time.Duration(settings.LatencyMS) * time.Millisecond
+	if rand.Float64() < settings.SlowRate {
+		delay += time.Duration(settings.SlowDelayMS) * time.Millisecond
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-c.Request.Context().Done():
+			c.Abort()
+			return
+		}
+	}
+	c.Next()
+}
+
+// captchaPage and forbiddenPage imitate the pages bot protection serves
+// instead of a site
+const (
+	captchaPage = `<!DOCTYPE html>
+<html><head><title>Just a moment...</title></head>
+<body><div class="cf-turnstile" data-sitekey="mock"></div>
+<script src="https://challenges.cloudflare.com/turnstile/v0/api.js"></script></body></html>`
+	forbiddenPage = `<!DOCTYPE html>
+<html><head><title>403 Forbidden</title></head><body><h1>Forbidden</h1></body></html>`
+)
+
+// pageParam reads a positive page number query parameter
+func pageParam(c *gin.Context, name string, defaultValue int) int {
+	page, err := strconv.Atoi(c.Query(name))
+	if err != nil || page < 1 {
+		return defaultValue
+	}
+	return page
+}
+
+// paginate returns one page of fixtures
+func paginate(fixtures []fixture, page, size int) []fixture {
+	start := (page - 1) * size
+	if start >= len(fixtures) {
+		return nil
+	}
+	end := start + size
+	if end > len(fixtures) {
+		end = len(fixtures)
+	}
+	return fixtures[start:end]
+}
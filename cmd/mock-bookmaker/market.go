@@ -0,0 +1,137 @@
+package main
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// eat is Kenyan time, which the fake sites show kickoffs in
+var eat = time.FixedZone("EAT", 3*60*60)
+
+var leagues = []struct {
+	name, country string
+	teams         []string
+}{
+	{"Premier League", "England", []string{"Arsenal", "Chelsea", "Liverpool", "Manchester United", "Manchester City", "Tottenham", "Newcastle", "Aston Villa"}},
+	{"Premier League", "Kenya", []string{"Gor Mahia", "AFC Leopards", "Tusker FC", "Kenya Police", "Kakamega Homeboyz", "Bandari", "Sofapaka", "KCB"}},
+	{"LaLiga", "Spain", []string{"Barcelona", "Real Madrid", "Atletico Madrid", "Sevilla", "Valencia", "Villarreal"}},
+}
+
+// fixture is one fake match offered by every mock site
+type fixture struct {
+	ID      int       `json:"id"`
+	Home    string    `json:"home"`
+	Away    string    `json:"away"`
+	League  string    `json:"league"`
+	Country string    `json:"country"`
+	Kickoff time.Time `json:"kickoff"`
+
+	// Fair probabilities the sites price from
+	home, draw, over, btts float64
+}
+
+// prices are one site's prices for a fixture at a point in time
+type prices struct {
+	Home, Draw, Away float64
+	Over, Under      float64
+	Yes, No          float64
+}
+
+// market generates fixtures and moves their prices as time passes
+type market struct {
+	start    time.Time
+	tick     time.Duration
+	fixtures []fixture
+}
+
+func newMarket(count int, seed int64, tick time.Duration, start time.Time) *market {
+	rng := rand.New(rand.NewSource(seed))
+	m := &market{start: start, tick: tick}
+
+	// The first kickoffs are close, so kickoff-proximity refreshes have work
+	first := start.Truncate(15 * time.Minute).Add(45 * time.Minute)
+	for i := 0; i < count; i++ {
+		league := leagues[i%len(leagues)]
+		order := rng.Perm(len(league.teams))
+		home := 0.25 + rng.Float64()*0.35
+		draw := 0.22 + rng.Float64()*0.08
+		if home+draw > 0.85 {
+			draw = 0.85 - home
+		}
+
+		m.fixtures = append(m.fixtures, fixture{
+			ID:      4100000 + i*37,
+			Home:    league.teams[order[0]],
+			Away:    league.teams[order[1]],
+			League:  league.name,
+			Country: league.country,
+			Kickoff: first.Add(time.Duration(i*i) * 45 * time.Minute),
+			home:    home,
+			draw:    draw,
+			over:    0.4 + rng.Float64()*0.2,
+			btts:    0.45 + rng.Float64()*0.15,
+		})
+	}
+	return m
+}
+
+// step is the number of price ticks since the market opened
+func (m *market) step(now time.Time) int {
+	return int(now.Sub(m.start) / m.tick)
+}
+
+// upcoming returns the fixtures that have not kicked off
+func (m *market) upcoming(now time.Time) []fixture {
+	var upcoming []fixture
+	for _, f := range m.fixtures {
+		if f.Kickoff.After(now) {
+			upcoming = append(upcoming, f)
+		}
+	}
+	return upcoming
+}
+
+// prices returns a site's prices for a fixture, and whether its markets are
+// suspended. Each site has its own margin and each price drifts on its own
+// cycle, so sites disagree and occasionally leave arbitrage open.
+func (m *market) prices(site string, f fixture, now time.Time, suspendEvery int) (prices, bool) {
+	step := m.step(now)
+	seed := hash(site, f.ID)
+	if suspendEvery > 0 && (step+int(seed%97))%suspendEvery == 0 {
+		return prices{}, true
+	}
+
+	margin := 1.04 + float64(seed%5)/100
+	drift := 0.06 * math.Sin(float64(step)*0.9+float64(seed%628)/100)
+
+	home := f.home * (1 + drift)
+	away := 1 - f.home - f.draw
+	away = away * (1 - drift)
+	return prices{
+		Home:  odds(home, margin),
+		Draw:  odds(f.draw, margin),
+		Away:  odds(away, margin),
+		Over:  odds(f.over*(1+drift/2), margin),
+		Under: odds((1-f.over)*(1-drift/2), margin),
+		Yes:   odds(f.btts*(1-drift/2), margin),
+		No:    odds((1-f.btts)*(1+drift/2), margin),
+	}, false
+}
+
+// odds turns a probability into a price with the bookmaker's margin
+func odds(probability, margin float64) float64 {
+	price := 1 / (probability * margin)
+	if price < 1.01 {
+		price = 1.01
+	}
+	return math.Round(price*100) / 100
+}
+
+func hash(site string, id int) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(site))
+	h.Write([]byte{byte(id), byte(id >> 8), byte(id >> 16)})
+	return h.Sum32()
+}
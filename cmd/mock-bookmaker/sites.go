@@ -0,0 +1,266 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sportpesaCookie is the session cookie SportPesa's API insists on
+const sportpesaCookie = "spkessid"
+
+// row is a fixture with one site's prices, as the HTML pages show it
+type row struct {
+	fixture
+	Odds      prices
+	Suspended bool
+	Time      string
+}
+
+// rows prices the upcoming fixtures for a site's HTML pages
+func (m *mock) rows(site string, fixtures []fixture, now time.Time, timeLayout string) []row {
+	suspendEvery := m.current().SuspendEvery
+	rows := make([]row, 0, len(fixtures))
+	for _, f := range fixtures {
+		p, suspended := m.market.prices(site, f, now, suspendEvery)
+		rows = append(rows, row{fixture: f, Odds: p, Suspended: suspended, Time: f.Kickoff.In(eat).Format(timeLayout)})
+	}
+	return rows
+}
+
+// price formats a price the way the sites do, with "0.00" for suspended
+// markets
+func price(value float64, suspended bool) string {
+	if suspended {
+		return "0.00"
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+var pages = template.Must(template.New("pages").Funcs(template.FuncMap{
+	"price": price,
+}).Parse(`
+{{define "shell"}}<!DOCTYPE html>
+<html>
+<head><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<div id="events">Loading...</div>
+<script>
+fetch({{.API}}, {credentials: "same-origin"})
+  .then(function (r) { return r.json(); })
+  .then(function (data) {
+    document.getElementById("events").textContent = JSON.stringify(data).length + " bytes of odds loaded";
+  });
+</script>
+</body>
+</html>{{end}}
+
+{{define "mozzartbet"}}<!DOCTYPE html>
+<html>
+<head><title>Mozzartbet</title></head>
+<body>
+<div class="matches">
+{{range .Rows}}<div class="{{$.RowClass}}" data-match-id="{{.ID}}">
+  <span class="competition-name">{{.League}}</span>
+  <span class="match-time">{{.Time}}</span>
+  <span class="home-team">{{.Home}}</span>
+  <span class="away-team">{{.Away}}</span>
+  <div class="odds-1x2"><span class="odd">{{price .Odds.Home .Suspended}}</span><span class="odd">{{price .Odds.Draw .Suspended}}</span><span class="odd">{{price .Odds.Away .Suspended}}</span></div>
+  <div class="odds-total"><span class="odd">{{price .Odds.Over .Suspended}}</span><span class="odd">{{price .Odds.Under .Suspended}}</span></div>
+</div>
+{{end}}</div>
+{{if .Next}}<button class="load-more" onclick="location.search='?batch={{.Next}}'">Load more</button>{{end}}
+</body>
+</html>{{end}}
+
+{{define "22bet"}}<!DOCTYPE html>
+<html>
+<head><title>22Bet</title></head>
+<body>
+<div class="c-events">
+{{range .Rows}}<div class="{{$.RowClass}}">
+  <div class="c-events__liga">{{.League}}</div>
+  <div class="c-events__time"><span>{{.Time}}</span></div>
+  <div class="c-events__teams"><span class="c-events__team">{{.Home}}</span><span class="c-events__team">{{.Away}}</span></div>
+  <div class="c-bets">
+    <div class="c-bets__bet" data-type="1"><span class="c-bets__inner">{{price .Odds.Home .Suspended}}</span></div>
+    <div class="c-bets__bet" data-type="2"><span class="c-bets__inner">{{price .Odds.Draw .Suspended}}</span></div>
+    <div class="c-bets__bet" data-type="3"><span class="c-bets__inner">{{price .Odds.Away .Suspended}}</span></div>
+    <div class="c-bets__bet" data-type="180"><span class="c-bets__inner">{{price .Odds.Yes .Suspended}}</span></div>
+    <div class="c-bets__bet" data-type="181"><span class="c-bets__inner">{{price .Odds.No .Suspended}}</span></div>
+  </div>
+</div>
+{{end}}</div>
+</body>
+</html>{{end}}
+`))
+
+func render(c *gin.Context, name string, data gin.H) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(c.Writer, name, data); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// betikaRoutes serves Betika's football page and the match list API it
+// loads its odds from
+func (m *mock) betikaRoutes(group *gin.RouterGroup) {
+	group.GET("/en-ke/sport/football", func(c *gin.Context) {
+		render(c, "shell", gin.H{
+			"Title": "Betika Football",
+			"API":   group.BasePath() + "/v1/uo/matches?page=1&limit=50&sport_id=14&sub_type_id=1,18,29",
+		})
+	})
+
+	group.GET("/v1/uo/matches", func(c *gin.Context) {
+		now := time.Now()
+		v2 := m.layout(now) == layoutV2
+		suspendEvery := m.current().SuspendEvery
+		page := paginate(m.market.upcoming(now), pageParam(c, "page", 1), pageParam(c, "limit", 50))
+
+		events := make([]gin.H, 0, len(page))
+		for _, f := range page {
+			p, suspended := m.market.prices("betika", f, now, suspendEvery)
+			event := gin.H{
+				"match_id":         strconv.Itoa(f.ID),
+				"start_time":       f.Kickoff.In(eat).Format("2006-01-02 15:04:05"),
+				"competition_name": f.League,
+				"category":         f.Country,
+				"home_odd":         price(p.Home, suspended),
+				"neutral_odd":      price(p.Draw, suspended),
+				"away_odd":         price(p.Away, suspended),
+				"odds": []gin.H{
+					{"sub_type_id": "18", "odds": []gin.H{
+						{"odd_key": "over 2.5", "odd_value": price(p.Over, suspended), "special_bet_value": "total=2.5"},
+						{"odd_key": "under 2.5", "odd_value": price(p.Under, suspended), "special_bet_value": "total=2.5"},
+					}},
+					{"sub_type_id": "29", "odds": []gin.H{
+						{"odd_key": "yes", "odd_value": price(p.Yes, suspended)},
+						{"odd_key": "no", "odd_value": price(p.No, suspended)},
+					}},
+				},
+			}
+			if v2 {
+				event["competitors"] = []string{f.Home, f.Away}
+			} else {
+				event["home_team"] = f.Home
+				event["away_team"] = f.Away
+			}
+			events = append(events, event)
+		}
+
+		if v2 {
+			c.JSON(http.StatusOK, gin.H{"events": events, "meta": gin.H{"total": len(m.market.upcoming(now))}})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": events, "meta": gin.H{"total": len(m.market.upcoming(now))}})
+	})
+}
+
+// sportpesaRoutes serves SportPesa's football page, which hands out the
+// session cookie, and the upcoming games API, which refuses requests
+// without it
+func (m *mock) sportpesaRoutes(group *gin.RouterGroup) {
+	group.GET("/en/sport/football", func(c *gin.Context) {
+		c.SetCookie(sportpesaCookie, strconv.FormatInt(time.Now().UnixNano(), 36), 3600, group.BasePath(), "", false, true)
+		render(c, "shell", gin.H{
+			"Title": "SportPesa Football",
+			"API":   group.BasePath() + "/api/upcoming/games?type=prematch&sportId=1&section=upcoming&pag_count=50&pag_min=1",
+		})
+	})
+
+	group.GET("/api/upcoming/games", func(c *gin.Context) {
+		if _, err := c.Cookie(sportpesaCookie); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "session required"})
+			return
+		}
+
+		now := time.Now()
+		v2 := m.layout(now) == layoutV2
+		suspendEvery := m.current().SuspendEvery
+		size := pageParam(c, "pag_count", 50)
+		page := paginate(m.market.upcoming(now), (pageParam(c, "pag_min", 1)-1)/size+1, size)
+
+		games := make([]gin.H, 0, len(page))
+		for _, f := range page {
+			p, suspended := m.market.prices("sportpesa", f, now, suspendEvery)
+			odds := func(value float64) string { return price(value, suspended) }
+			competitors := []gin.H{{"name": f.Home}, {"name": f.Away}}
+
+			game := gin.H{
+				"id":            f.ID,
+				"dateTimestamp": f.Kickoff.UnixMilli(),
+				"competition":   gin.H{"name": f.League},
+				"country":       gin.H{"name": f.Country},
+				"markets": []gin.H{
+					{"id": 10, "name": "3 Way", "specValue": 0, "selections": []gin.H{
+						{"shortName": "1", "odds": odds(p.Home)},
+						{"shortName": "X", "odds": odds(p.Draw)},
+						{"shortName": "2", "odds": odds(p.Away)},
+					}},
+					{"id": 52, "name": "Total Goals", "specValue": 2.5, "selections": []gin.H{
+						{"shortName": "OV", "odds": odds(p.Over)},
+						{"shortName": "UN", "odds": odds(p.Under)},
+					}},
+					{"id": 43, "name": "Both Teams To Score", "specValue": 0, "selections": []gin.H{
+						{"shortName": "YES", "odds": odds(p.Yes)},
+						{"shortName": "NO", "odds": odds(p.No)},
+					}},
+				},
+			}
+			if v2 {
+				game["teams"] = competitors
+			} else {
+				game["competitors"] = competitors
+			}
+			games = append(games, game)
+		}
+		c.JSON(http.StatusOK, games)
+	})
+}
+
+// mozzartbetRoutes serves the Mozzartbet list sites/mozzartbet.yaml reads,
+// 5 rows at a time with a load-more button while more remain
+func (m *mock) mozzartbetRoutes(group *gin.RouterGroup) {
+	group.GET("/en", func(c *gin.Context) {
+		now := time.Now()
+		rowClass := "match-row"
+		if m.layout(now) == layoutV2 {
+			rowClass = "event-row"
+		}
+
+		// The page only grows through the load-more button, which replays
+		// the list with one more batch in the browser
+		upcoming := m.market.upcoming(now)
+		batch := pageParam(c, "batch", 1)
+		shown, next := 5*batch, batch+1
+		if shown >= len(upcoming) {
+			shown, next = len(upcoming), 0
+		}
+		render(c, "mozzartbet", gin.H{
+			"RowClass": rowClass,
+			"Rows":     m.rows("mozzartbet", upcoming[:shown], now, "02.01. 15:04"),
+			"Next":     next,
+		})
+	})
+}
+
+// bet22Routes serves the paged 22Bet football line sites/22bet.yaml reads
+func (m *mock) bet22Routes(group *gin.RouterGroup) {
+	group.GET("/line/football", func(c *gin.Context) {
+		now := time.Now()
+		rowClass := "c-events__item c-events__item_game"
+		if m.layout(now) == layoutV2 {
+			rowClass = "c-events__item c-events__item_event"
+		}
+
+		render(c, "22bet", gin.H{
+			"RowClass": rowClass,
+			"Rows":     m.rows("22bet", paginate(m.market.upcoming(now), pageParam(c, "page", 1), 8), now, "02.01 15:04"),
+		})
+	})
+}
@@ -0,0 +1,173 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// outcomeSets lists, per market, the selections that together cover every
+// outcome. Asian handicap lines are quoted from the home side, so home and
+// away at the same line are complementary.
+var outcomeSets = map[string][]string{
+	models.MarketMatchResult:   {models.SelectionHome, models.SelectionDraw, models.SelectionAway},
+	models.MarketOverUnder:     {models.SelectionOver, models.SelectionUnder},
+	models.MarketBTTS:          {models.SelectionYes, models.SelectionNo},
+	models.MarketDrawNoBet:     {models.SelectionHome, models.SelectionAway},
+	models.MarketAsianHandicap: {models.SelectionHome, models.SelectionAway},
+}
+
+// ArbitrageOptions controls an arbitrage scan
+type ArbitrageOptions struct {
+	// Stake is the total amount in KES to split across the legs
+	Stake float64
+	// MaxOddsAge ignores prices scraped longer ago than this; zero keeps all
+	MaxOddsAge time.Duration
+	// Now is the reference time for odds age, defaulting to time.Now
+	Now time.Time
+	// Tax ranks and sizes legs on after-tax net odds per site when set
+	Tax map[string]models.TaxProfile
+}
+
+// ArbitrageFilter narrows a list of arbitrage opportunities
+type ArbitrageFilter struct {
+	MinProfitPercent float64
+	Sport            string
+	KickoffFrom      time.Time
+	KickoffTo        time.Time
+}
+
+// FindArbitrage scans every fixture and market for a combination of best
+// prices whose implied probabilities sum below 1, most profitable first.
+func FindArbitrage(bestOdds []models.BestOdds, opts ArbitrageOptions) []models.Arbitrage {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	arbitrages := make([]models.Arbitrage, 0)
+	for _, fixture := range bestOdds {
+		// Rebuild best prices from fresh odds only
+		best := make(map[string]models.ArbitrageLeg)
+		groups := make(map[string]models.Price)
+		for _, odd := range fixture.AllOdds {
+			if opts.MaxOddsAge > 0 && now.Sub(odd.ScrapedAt) > opts.MaxOddsAge {
+				continue
+			}
+			for _, price := range odd.Markets {
+				if _, ok := outcomeSets[price.Market]; !ok || price.Value <= 1 {
+					continue
+				}
+				leg := models.ArbitrageLeg{
+					Selection:  price.Selection,
+					Value:      price.Value,
+					SiteID:     odd.SiteID,
+					SiteName:   odd.SiteName,
+					Provenance: odd.Provenance,
+					ScrapedAt:  odd.ScrapedAt,
+				}
+				if opts.Tax != nil {
+					leg.NetValue = NetOdds(price.Value, opts.Tax[odd.SiteID])
+				}
+
+				key := price.Key()
+				if current, exists := best[key]; exists && effectiveOdds(current) >= effectiveOdds(leg) {
+					continue
+				}
+				best[key] = leg
+				group := models.Price{Market: price.Market, Line: price.Line, Period: price.Period}
+				groups[group.Key()] = group
+			}
+		}
+
+		for groupKey, group := range groups {
+			legs := make([]models.ArbitrageLeg, 0, len(outcomeSets[group.Market]))
+			implied := 0.0
+			for _, selection := range outcomeSets[group.Market] {
+				key := models.Price{Market: group.Market, Line: group.Line, Period: group.Period, Selection: selection}.Key()
+				leg, exists := best[key]
+				if !exists {
+					break
+				}
+				legs = append(legs, leg)
+				implied += 1 / effectiveOdds(leg)
+			}
+			if len(legs) != len(outcomeSets[group.Market]) || implied >= 1 {
+				continue
+			}
+
+			arbitrage := models.Arbitrage{
+				ID:                 fixture.Match.ID + "|" + groupKey,
+				Match:              fixture.Match,
+				Market:             group.Market,
+				Line:               group.Line,
+				Period:             group.Period,
+				ImpliedProbability: implied,
+				ProfitPercent:      (1/implied - 1) * 100,
+				Legs:               legs,
+				DetectedAt:         now,
+			}
+			arbitrages = append(arbitrages, SplitStake(arbitrage, opts.Stake))
+		}
+	}
+
+	sort.Slice(arbitrages, func(i, j int) bool {
+		if arbitrages[i].ProfitPercent != arbitrages[j].ProfitPercent {
+			return arbitrages[i].ProfitPercent > arbitrages[j].ProfitPercent
+		}
+		return arbitrages[i].ID < arbitrages[j].ID
+	})
+	return arbitrages
+}
+
+// SplitStake distributes a total stake across the legs in proportion to their
+// implied probabilities, so every outcome returns the same amount. For net
+// arbitrage the stakes are amounts paid in and returns are after tax.
+func SplitStake(arbitrage models.Arbitrage, total float64) models.Arbitrage {
+	legs := make([]models.ArbitrageLeg, len(arbitrage.Legs))
+	copy(legs, arbitrage.Legs)
+
+	for i, leg := range legs {
+		stake := total * (1 / effectiveOdds(leg)) / arbitrage.ImpliedProbability
+		legs[i].Stake = roundKES(stake)
+		legs[i].Return = roundKES(stake * effectiveOdds(leg))
+	}
+
+	arbitrage.Legs = legs
+	arbitrage.TotalStake = total
+	return arbitrage
+}
+
+// FilterArbitrage returns the opportunities matching every set filter field
+func FilterArbitrage(arbitrages []models.Arbitrage, filter ArbitrageFilter) []models.Arbitrage {
+	filtered := make([]models.Arbitrage, 0, len(arbitrages))
+	for _, arbitrage := range arbitrages {
+		switch {
+		case arbitrage.ProfitPercent < filter.MinProfitPercent:
+			continue
+		case filter.Sport != "" && arbitrage.Match.Sport != filter.Sport:
+			continue
+		case !filter.KickoffFrom.IsZero() && arbitrage.Match.MatchTime.Before(filter.KickoffFrom):
+			continue
+		case !filter.KickoffTo.IsZero() && arbitrage.Match.MatchTime.After(filter.KickoffTo):
+			continue
+		}
+		filtered = append(filtered, arbitrage)
+	}
+	return filtered
+}
+
+// effectiveOdds is the price a leg is ranked and sized on: net of tax when
+// that has been computed, otherwise the raw price
+func effectiveOdds(leg models.ArbitrageLeg) float64 {
+	if leg.NetValue > 0 {
+		return leg.NetValue
+	}
+	return leg.Value
+}
+
+func roundKES(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+func TestSplitStake(t *testing.T) {
+	tests := []struct {
+		name    string
+		prices  []float64
+		total   float64
+		stakes  []float64
+		returns []float64
+	}{
+		// Equal prices split evenly
+		{"even", []float64{2.1, 2.1}, 1000, []float64{500, 500}, []float64{1050, 1050}},
+		// 1/1.5 + 1/4 = 0.916667, so 1000 returns 1090.91 either way
+		{"uneven", []float64{1.5, 4}, 1000, []float64{727.27, 272.73}, []float64{1090.91, 1090.91}},
+		// 1/2.5 + 1/3.6 + 1/6 = 0.844444
+		{"three way", []float64{2.5, 3.6, 6}, 500, []float64{236.84, 164.47, 98.68}, []float64{592.11, 592.11, 592.11}},
+	}
+	for _, tt := range tests {
+		arbitrage := models.Arbitrage{}
+		for _, price := range tt.prices {
+			arbitrage.Legs = append(arbitrage.Legs, models.ArbitrageLeg{Value: price})
+			arbitrage.ImpliedProbability += 1 / price
+		}
+
+		split := SplitStake(arbitrage, tt.total)
+		if split.TotalStake != tt.total {
+			t.Errorf("%s: total stake %v, want %v", tt.name, split.TotalStake, tt.total)
+		}
+		for i, leg := range split.Legs {
+			if leg.Stake != tt.stakes[i] || leg.Return != tt.returns[i] {
+				t.Errorf("%s: leg %d stakes %v to return %v, want %v to return %v", tt.name, i, leg.Stake, leg.Return, tt.stakes[i], tt.returns[i])
+			}
+		}
+		if arbitrage.Legs[0].Stake != 0 {
+			t.Errorf("%s: SplitStake changed the legs it was given", tt.name)
+		}
+	}
+}
+
+func TestFindArbitrage(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	odds := func(siteID string, yes, no float64, age time.Duration) models.Odds {
+		odd := models.Odds{SiteID: siteID, SiteName: siteID, ScrapedAt: now.Add(-age)}
+		odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes, yes)
+		odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionNo, no)
+		return odd
+	}
+	fixture := models.BestOdds{
+		Match: models.Match{ID: "arsenal_vs_chelsea"},
+		AllOdds: []models.Odds{
+			odds("betika", 1.5, 1.2, time.Minute),
+			odds("sportpesa", 1.3, 4, time.Minute),
+			// A stale price that would otherwise be best
+			odds("betway", 1.9, 1.9, time.Hour),
+		},
+	}
+
+	arbitrages := FindArbitrage([]models.BestOdds{fixture}, ArbitrageOptions{Stake: 1000, MaxOddsAge: 15 * time.Minute, Now: now})
+	if len(arbitrages) != 1 {
+		t.Fatalf("found %d arbitrages, want 1", len(arbitrages))
+	}
+	arbitrage := arbitrages[0]
+	if math.Abs(arbitrage.ProfitPercent-9.0909) > 1e-4 {
+		t.Errorf("profit %.4f%%, want 9.0909%%", arbitrage.ProfitPercent)
+	}
+	want := []struct {
+		site  string
+		stake float64
+	}{{"betika", 727.27}, {"sportpesa", 272.73}}
+	for i, leg := range arbitrage.Legs {
+		if leg.SiteID != want[i].site || leg.Stake != want[i].stake {
+			t.Errorf("leg %d is %s for %v, want %s for %v", i, leg.SiteID, leg.Stake, want[i].site, want[i].stake)
+		}
+	}
+
+	// After Kenyan tax the same prices net 1.244 and 3.022, which lose money
+	tax := map[string]models.TaxProfile{"betika": models.KenyaTaxProfile, "sportpesa": models.KenyaTaxProfile}
+	if net := FindArbitrage([]models.BestOdds{fixture}, ArbitrageOptions{Stake: 1000, MaxOddsAge: 15 * time.Minute, Now: now, Tax: tax}); len(net) != 0 {
+		t.Errorf("found %d arbitrages after tax, want none", len(net))
+	}
+}
@@ -0,0 +1,299 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// De-vig methods for turning a bookmaker's prices into fair probabilities
+const (
+	DevigProportional = "proportional"
+	DevigShin         = "shin"
+	DevigPower        = "power"
+)
+
+// ValidDevigMethod reports whether method is a supported de-vig method
+func ValidDevigMethod(method string) bool {
+	switch method {
+	case DevigProportional, DevigShin, DevigPower:
+		return true
+	}
+	return false
+}
+
+// Overround is the amount by which a market's implied probabilities exceed 1
+func Overround(prices []float64) float64 {
+	total := 0.0
+	for _, price := range prices {
+		total += 1 / price
+	}
+	return total - 1
+}
+
+// FairProbabilities removes the bookmaker margin from a complete market using
+// the given method, returning probabilities that sum to 1.
+func FairProbabilities(prices []float64, method string) ([]float64, error) {
+	implied := make([]float64, len(prices))
+	total := 0.0
+	for i, price := range prices {
+		if price <= 1 {
+			return nil, fmt.Errorf("invalid price %.3f", price)
+		}
+		implied[i] = 1 / price
+		total += implied[i]
+	}
+
+	switch method {
+	case DevigProportional, "":
+		return proportional(implied, total), nil
+	case DevigShin:
+		if total <= 1 {
+			// Shin's model needs a positive margin to attribute to insiders
+			return proportional(implied, total), nil
+		}
+		return shin(implied, total), nil
+	case DevigPower:
+		return power(implied), nil
+	}
+	return nil, fmt.Errorf("unknown de-vig method %q", method)
+}
+
+// proportional scales every implied probability down by the same factor
+func proportional(implied []float64, total float64) []float64 {
+	fair := make([]float64, len(implied))
+	for i, p := range implied {
+		fair[i] = p / total
+	}
+	return fair
+}
+
+// shin models the margin as protection against insider trading, which takes
+// more margin from longshots than from favourites. It solves for the insider
+// share z at which the fair probabilities sum to 1.
+func shin(implied []float64, total float64) []float64 {
+	probabilities := func(z float64) ([]float64, float64) {
+		fair := make([]float64, len(implied))
+		sum := 0.0
+		for i, p := range implied {
+			fair[i] = (math.Sqrt(z*z+4*(1-z)*p*p/total) - z) / (2 * (1 - z))
+			sum += fair[i]
+		}
+		return fair, sum
+	}
+
+	// The sum falls as z rises, so bisect for the z where it reaches 1
+	low, high := 0.0, 0.5
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if _, sum := probabilities(mid); sum > 1 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	fair, _ := probabilities((low + high) / 2)
+	return fair
+}
+
+// power raises every implied probability to the exponent k at which they sum
+// to 1, which likewise shades longshots more than favourites
+func power(implied []float64) []float64 {
+	sum := func(k float64) float64 {
+		total := 0.0
+		for _, p := range implied {
+			total += math.Pow(p, k)
+		}
+		return total
+	}
+
+	low, high := 0.5, 10.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if sum(mid) > 1 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	k := (low + high) / 2
+	fair := make([]float64, len(implied))
+	for i, p := range implied {
+		fair[i] = math.Pow(p, k)
+	}
+	return fair
+}
+
+// MarketMargins computes the overround and fair prices of every complete
+// market in an odds row
+func MarketMargins(odd models.Odds, method string) ([]models.MarketMargin, error) {
+	groups := make(map[string]models.Price)
+	for _, price := range odd.Markets {
+		if _, ok := outcomeSets[price.Market]; ok {
+			group := models.Price{Market: price.Market, Line: price.Line, Period: price.Period}
+			groups[group.Key()] = group
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	margins := make([]models.MarketMargin, 0, len(groups))
+	for _, key := range keys {
+		group := groups[key]
+		selections := outcomeSets[group.Market]
+		prices := make([]float64, 0, len(selections))
+		for _, selection := range selections {
+			value, exists := odd.Price(group.Market, group.Line, group.Period, selection)
+			if !exists || value <= 1 {
+				break
+			}
+			prices = append(prices, value)
+		}
+		if len(prices) != len(selections) {
+			continue
+		}
+
+		fair, err := FairProbabilities(prices, method)
+		if err != nil {
+			return nil, err
+		}
+
+		margin := models.MarketMargin{
+			Market:        group.Market,
+			Line:          group.Line,
+			Period:        group.Period,
+			Overround:     Overround(prices),
+			MarginPercent: round(Overround(prices)*100, 3),
+			Method:        method,
+		}
+		for i, selection := range selections {
+			margin.Selections = append(margin.Selections, models.FairPrice{
+				Selection:       selection,
+				Value:           prices[i],
+				FairProbability: round(fair[i], 4),
+				FairOdds:        round(1/fair[i], 3),
+			})
+		}
+		margins = append(margins, margin)
+	}
+	return margins, nil
+}
+
+// SiteMarginsFor computes market margins for every site's odds on every match
+func SiteMarginsFor(bestOdds []models.BestOdds, method string) ([]models.SiteMargins, error) {
+	result := make([]models.SiteMargins, 0)
+	for _, fixture := range bestOdds {
+		for _, odd := range fixture.AllOdds {
+			margins, err := MarketMargins(odd, method)
+			if err != nil {
+				return nil, err
+			}
+			if len(margins) == 0 {
+				continue
+			}
+			result = append(result, models.SiteMargins{
+				MatchID:  fixture.Match.ID,
+				SiteID:   odd.SiteID,
+				SiteName: odd.SiteName,
+				Markets:  margins,
+			})
+		}
+	}
+	return result, nil
+}
+
+// LeagueMarginTable averages each site's margin on a market per sport and
+// league, cheapest site first within each league. An empty market compares
+// the full-time match result, which every site prices; averaging over every
+// market would rank sites on whichever markets they happen to offer.
+func LeagueMarginTable(bestOdds []models.BestOdds, market string) []models.LeagueMargin {
+	period := ""
+	if market == "" {
+		market, period = models.MarketMatchResult, models.PeriodFullTime
+	}
+
+	type total struct {
+		entry models.LeagueMargin
+		sum   float64
+	}
+	totals := make(map[string]*total)
+
+	for _, fixture := range bestOdds {
+		for _, odd := range fixture.AllOdds {
+			margins, _ := MarketMargins(odd, DevigProportional)
+			for _, margin := range margins {
+				if margin.Market != market || (period != "" && margin.Period != period) {
+					continue
+				}
+				key := fixture.Match.Sport + "|" + fixture.Match.League + "|" + odd.SiteID
+				if totals[key] == nil {
+					totals[key] = &total{entry: models.LeagueMargin{
+						Sport:    fixture.Match.Sport,
+						League:   fixture.Match.League,
+						SiteID:   odd.SiteID,
+						SiteName: odd.SiteName,
+					}}
+				}
+				totals[key].sum += margin.MarginPercent
+				totals[key].entry.Markets++
+			}
+		}
+	}
+
+	table := make([]models.LeagueMargin, 0, len(totals))
+	for _, t := range totals {
+		t.entry.MarginPercent = round(t.sum/float64(t.entry.Markets), 3)
+		table = append(table, t.entry)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Sport != b.Sport {
+			return a.Sport < b.Sport
+		}
+		if a.League != b.League {
+			return a.League < b.League
+		}
+		if a.MarginPercent != b.MarginPercent {
+			return a.MarginPercent < b.MarginPercent
+		}
+		return a.SiteID < b.SiteID
+	})
+
+	for i := range table {
+		table[i].Cheapest = i == 0 || table[i].Sport != table[i-1].Sport || table[i].League != table[i-1].League
+	}
+	return table
+}
+
+// SiteAverageMargins averages each site's margin across all complete markets
+func SiteAverageMargins(bestOdds []models.BestOdds) map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, fixture := range bestOdds {
+		for _, odd := range fixture.AllOdds {
+			margins, _ := MarketMargins(odd, DevigProportional)
+			for _, margin := range margins {
+				sums[odd.SiteID] += margin.MarginPercent
+				counts[odd.SiteID]++
+			}
+		}
+	}
+
+	averages := make(map[string]float64, len(sums))
+	for siteID, sum := range sums {
+		averages[siteID] = round(sum/float64(counts[siteID]), 3)
+	}
+	return averages
+}
+
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,135 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"betting-odds-scraper/internal/models"
+)
+
+func TestOverround(t *testing.T) {
+	tests := []struct {
+		prices []float64
+		want   float64
+	}{
+		{[]float64{2, 4, 4}, 0},
+		{[]float64{1.9, 1.9}, 0.052632},
+		{[]float64{2, 3, 4}, 0.083333},
+		{[]float64{2.1, 2.1}, -0.047619},
+	}
+	for _, tt := range tests {
+		if got := Overround(tt.prices); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Overround(%v) = %.6f, want %.6f", tt.prices, got, tt.want)
+		}
+	}
+}
+
+func TestFairProbabilities(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []float64
+		method string
+		want   []float64
+	}{
+		// 1/2 + 1/3 + 1/4 = 1.083333, scaled down evenly
+		{"proportional", []float64{2, 3, 4}, DevigProportional, []float64{0.461538, 0.307692, 0.230769}},
+		{"default", []float64{1.9, 1.9}, "", []float64{0.5, 0.5}},
+		// With two outcomes Shin takes half the 5% margin from each side:
+		// 0.8 - 0.025 and 0.25 - 0.025
+		{"shin", []float64{1.25, 4}, DevigShin, []float64{0.775, 0.225}},
+		// Without a margin Shin falls back to proportional
+		{"shin without margin", []float64{2.1, 2.1}, DevigShin, []float64{0.5, 0.5}},
+		// 0.8^k + 0.25^k = 1 at k = 1.09997
+		{"power", []float64{1.25, 4}, DevigPower, []float64{0.782352, 0.217648}},
+		{"power even", []float64{1.9, 1.9}, DevigPower, []float64{0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		fair, err := FairProbabilities(tt.prices, tt.method)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i := range tt.want {
+			if math.Abs(fair[i]-tt.want[i]) > 1e-5 {
+				t.Errorf("%s: fair probabilities %v, want %v", tt.name, fair, tt.want)
+				break
+			}
+		}
+	}
+
+	if _, err := FairProbabilities([]float64{1, 3}, DevigProportional); err == nil {
+		t.Error("accepted a price of 1")
+	}
+	if _, err := FairProbabilities([]float64{2, 2}, "median"); err == nil {
+		t.Error("accepted an unknown method")
+	}
+}
+
+func TestMarketMargins(t *testing.T) {
+	odd := models.Odds{SiteID: "betika"}
+	odd.SetMatchResult(2, 3, 4)
+	// An incomplete market is left out
+	odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes, 1.8)
+
+	margins, err := MarketMargins(odd, DevigProportional)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(margins) != 1 || margins[0].Market != models.MarketMatchResult {
+		t.Fatalf("got %+v, want only the match result", margins)
+	}
+	if margins[0].MarginPercent != 8.333 {
+		t.Errorf("margin %v%%, want 8.333%%", margins[0].MarginPercent)
+	}
+	// Fair odds are the prices times the overround: 2.167, 3.25 and 4.333
+	for i, want := range []float64{2.167, 3.25, 4.333} {
+		if got := margins[0].Selections[i].FairOdds; got != want {
+			t.Errorf("selection %d fair odds %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestLeagueMarginTable(t *testing.T) {
+	match := models.Match{ID: "arsenal_chelsea", Sport: "football", League: "Premier League"}
+	// betika is cheaper on the full-time match result and sportpesa on
+	// over/under; only betika prices the first half, at a 50% margin
+	betika := models.Odds{SiteID: "betika", SiteName: "Betika"}
+	betika.SetMatchResult(2, 3, 4)
+	for _, selection := range []string{models.SelectionHome, models.SelectionDraw, models.SelectionAway} {
+		betika.SetPrice(models.MarketMatchResult, "", models.PeriodFirstHalf, selection, 2)
+	}
+	betika.SetGoalMarkets(1.5, 1.5, 0, 0)
+	sportpesa := models.Odds{SiteID: "sportpesa", SiteName: "SportPesa"}
+	sportpesa.SetMatchResult(1.9, 2.9, 3.9)
+	sportpesa.SetGoalMarkets(1.95, 1.95, 0, 0)
+	bestOdds := []models.BestOdds{{Match: match, AllOdds: []models.Odds{betika, sportpesa}}}
+
+	type row struct {
+		siteID  string
+		margin  float64
+		markets int
+	}
+	tests := []struct {
+		market string
+		want   []row
+	}{
+		// The default compares the full-time match result alone
+		{"", []row{{"betika", 8.333, 1}, {"sportpesa", 12.755, 1}}},
+		// A named market averages over every period
+		{models.MarketMatchResult, []row{{"sportpesa", 12.755, 1}, {"betika", 29.167, 2}}},
+		{models.MarketOverUnder, []row{{"sportpesa", 2.564, 1}, {"betika", 33.333, 1}}},
+	}
+	for _, tt := range tests {
+		table := LeagueMarginTable(bestOdds, tt.market)
+		if len(table) != len(tt.want) {
+			t.Errorf("market %q: got %+v, want %d sites", tt.market, table, len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			got := table[i]
+			if got.SiteID != want.siteID || math.Abs(got.MarginPercent-want.margin) > 0.001 || got.Markets != want.markets || got.Cheapest != (i == 0) {
+				t.Errorf("market %q row %d: got %+v, want %+v", tt.market, i, got, want)
+			}
+		}
+	}
+}
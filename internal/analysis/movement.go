@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"sort"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// PriceChanges compares a site's fresh odds against its previous scrape and
+// returns a point for every selection that is new or whose price moved
+func PriceChanges(previous, current []models.Odds) []models.PricePoint {
+	last := make(map[string]float64)
+	for _, odd := range previous {
+		for _, price := range odd.Markets {
+			last[odd.MatchID+"|"+price.Key()] = price.Value
+		}
+	}
+
+	points := make([]models.PricePoint, 0)
+	for _, odd := range current {
+		for _, price := range odd.Markets {
+			if price.Value <= 0 {
+				continue
+			}
+			if value, exists := last[odd.MatchID+"|"+price.Key()]; exists && value == price.Value {
+				continue
+			}
+			points = append(points, models.PricePoint{
+				MatchID:    odd.MatchID,
+				SiteID:     odd.SiteID,
+				Market:     price.Market,
+				Line:       price.Line,
+				Period:     price.Period,
+				Selection:  price.Selection,
+				Value:      price.Value,
+				RecordedAt: odd.ScrapedAt,
+			})
+		}
+	}
+	return points
+}
+
+// PriceMovements groups a fixture's price points into one series per site and
+// selection, with the opening and current price and the drift between them.
+// Points must be ordered oldest first.
+func PriceMovements(points []models.PricePoint, siteNames map[string]string) []models.PriceMovement {
+	series := make(map[string]*models.PriceMovement)
+	for _, point := range points {
+		price := models.Price{Market: point.Market, Line: point.Line, Period: point.Period, Selection: point.Selection}
+		key := price.Key() + "|" + point.SiteID
+
+		movement := series[key]
+		if movement == nil {
+			movement = &models.PriceMovement{
+				Market:    point.Market,
+				Line:      point.Line,
+				Period:    point.Period,
+				Selection: point.Selection,
+				SiteID:    point.SiteID,
+				SiteName:  siteNames[point.SiteID],
+				Opening:   point.Value,
+				OpenedAt:  point.RecordedAt,
+			}
+			series[key] = movement
+		}
+		movement.History = append(movement.History, models.PriceTick{Value: point.Value, RecordedAt: point.RecordedAt})
+		movement.Current = point.Value
+		movement.UpdatedAt = point.RecordedAt
+	}
+
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	movements := make([]models.PriceMovement, 0, len(series))
+	for _, key := range keys {
+		movement := series[key]
+		movement.Changes = len(movement.History) - 1
+		movement.DriftPercent = round((movement.Current-movement.Opening)/movement.Opening*100, 2)
+		movements = append(movements, *movement)
+	}
+	return movements
+}
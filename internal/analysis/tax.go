@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// NetOdds converts a decimal price into the effective price a bettor gets back
+// per shilling paid in, after the site's excise duty and withholding tax.
+func NetOdds(price float64, tax models.TaxProfile) float64 {
+	if price <= 0 {
+		return 0
+	}
+
+	stake := 1.0
+	if !tax.AbsorbsExcise {
+		stake = 1 / (1 + tax.ExciseDuty)
+	}
+
+	payout := stake * price
+	taxable := payout - stake
+	if tax.WHTOnGross {
+		taxable = payout
+	}
+	if taxable < 0 {
+		taxable = 0
+	}
+
+	net := payout - taxable*tax.WithholdingTax
+	return math.Round(net*1000) / 1000
+}
+
+// LoadTaxProfiles reads per-site tax profile overrides from a JSON object
+// keyed by site ID. A missing file yields no overrides.
+func LoadTaxProfiles(path string) (map[string]models.TaxProfile, error) {
+	profiles := make(map[string]models.TaxProfile)
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax profiles: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse tax profiles %s: %w", path, err)
+	}
+	return profiles, nil
+}
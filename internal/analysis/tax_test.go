@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"testing"
+
+	"betting-odds-scraper/internal/models"
+)
+
+func TestNetOdds(t *testing.T) {
+	tests := []struct {
+		name  string
+		price float64
+		tax   models.TaxProfile
+		want  float64
+	}{
+		{"untaxed", 2, models.TaxProfile{}, 2},
+		// 1/1.125 staked returns 1.777778, less 20% of 0.888889 winnings
+		{"kenya evens", 2, models.KenyaTaxProfile, 1.6},
+		{"kenya favourite", 1.5, models.KenyaTaxProfile, 1.244},
+		{"kenya longshot", 4, models.KenyaTaxProfile, 3.022},
+		// The whole stake is placed, so only the winnings are taxed
+		{"absorbed excise", 2, models.TaxProfile{ExciseDuty: 0.125, AbsorbsExcise: true, WithholdingTax: 0.2}, 1.8},
+		{"tax on gross", 2, models.TaxProfile{WithholdingTax: 0.2, WHTOnGross: true}, 1.6},
+		// A price below evens has no winnings to tax
+		{"no winnings", 0.5, models.TaxProfile{WithholdingTax: 0.2}, 0.5},
+		{"no price", 0, models.KenyaTaxProfile, 0},
+	}
+	for _, tt := range tests {
+		if got := NetOdds(tt.price, tt.tax); got != tt.want {
+			t.Errorf("%s: NetOdds(%v) = %v, want %v", tt.name, tt.price, got, tt.want)
+		}
+	}
+}
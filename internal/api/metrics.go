@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/scraper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// breakerStateValues encode breaker states as a gauge
+var breakerStateValues = map[string]int{
+	scraper.BreakerClosed:   0,
+	scraper.BreakerHalfOpen: 1,
+	scraper.BreakerOpen:     2,
+}
+
+// metrics serves scrape counters and breaker state in the Prometheus text
+// format
+func (s *Server) metrics(c *gin.Context) {
+	sites := s.manager.Metrics()
+	var b strings.Builder
+
+	metric(&b, "scraper_attempts_total", "counter", "Scrape attempts, including retries")
+	for _, site := range sites {
+		fmt.Fprintf(&b, "scraper_attempts_total{site=%q} %d\n", site.SiteID, site.Attempts)
+	}
+
+	metric(&b, "scraper_retries_total", "counter", "Scrape attempts that retried a transient failure")
+	for _, site := range sites {
+		fmt.Fprintf(&b, "scraper_retries_total{site=%q} %d\n", site.SiteID, site.Retries)
+	}
+
+	metric(&b, "scraper_results_total", "counter", "Finished scrapes by status")
+	for _, site := range sites {
+		for _, status := range []string{models.ScrapeOK, models.ScrapeDegraded, models.ScrapeFailed} {
+			fmt.Fprintf(&b, "scraper_results_total{site=%q,status=%q} %d\n", site.SiteID, status, site.Results[status])
+		}
+	}
+
+	metric(&b, "scraper_errors_total", "counter", "Failed scrapes by error kind")
+	for _, site := range sites {
+		for _, kind := range models.ErrorKinds {
+			fmt.Fprintf(&b, "scraper_errors_total{site=%q,kind=%q} %d\n", site.SiteID, kind, site.Errors[kind])
+		}
+	}
+
+	metric(&b, "scraper_health", "gauge", "Site health score from 0 to 100")
+	for _, site := range sites {
+		fmt.Fprintf(&b, "scraper_health{site=%q} %g\n", site.SiteID, site.Health)
+	}
+
+	metric(&b, "scraper_breaker_state", "gauge", "Circuit breaker state: 0 closed, 1 half open, 2 open")
+	for _, site := range sites {
+		fmt.Fprintf(&b, "scraper_breaker_state{site=%q} %d\n", site.SiteID, breakerStateValues[site.Breaker.State])
+	}
+
+	metric(&b, "scraper_breaker_trips_total", "counter", "Times the circuit breaker opened after repeated failures")
+	for _, site := range sites {
+		fmt.Fprintf(&b, "scraper_breaker_trips_total{site=%q} %d\n", site.SiteID, site.Breaker.Trips)
+	}
+
+	metric(&b, "scraper_consecutive_failures", "gauge", "Failed scrapes since the last success")
+	for _, site := range sites {
+		fmt.Fprintf(&b, "scraper_consecutive_failures{site=%q} %d\n", site.SiteID, site.Breaker.Failures)
+	}
+
+	domains := s.manager.RateLimits()
+
+	metric(&b, "scraper_requests_total", "counter", "Requests sent to a bookmaker domain through its rate limiter")
+	for _, domain := range domains {
+		fmt.Fprintf(&b, "scraper_requests_total{domain=%q} %d\n", domain.Domain, domain.Requests)
+	}
+
+	metric(&b, "scraper_rate_limit_wait_seconds_total", "counter", "Time requests spent waiting for their domain's rate limit")
+	for _, domain := range domains {
+		fmt.Fprintf(&b, "scraper_rate_limit_wait_seconds_total{domain=%q} %g\n", domain.Domain, domain.Waited.Seconds())
+	}
+
+	metric(&b, "scraper_robots_disallowed_total", "counter", "Requests skipped because robots.txt disallows them")
+	for _, domain := range domains {
+		fmt.Fprintf(&b, "scraper_robots_disallowed_total{domain=%q} %d\n", domain.Domain, domain.Disallowed)
+	}
+
+	proxies := s.manager.Proxies()
+
+	metric(&b, "scraper_proxy_usable", "gauge", "Whether a proxy is healthy and not evicted (1) or out of rotation (0)")
+	for _, proxy := range proxies {
+		usable := 0
+		if proxy.Healthy && !proxy.Evicted {
+			usable = 1
+		}
+		fmt.Fprintf(&b, "scraper_proxy_usable{proxy=%q} %d\n", proxy.Proxy, usable)
+	}
+
+	metric(&b, "scraper_proxy_bans_total", "counter", "Times a site banned a proxy")
+	for _, proxy := range proxies {
+		fmt.Fprintf(&b, "scraper_proxy_bans_total{proxy=%q} %d\n", proxy.Proxy, proxy.Bans)
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+func metric(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
@@ -2,26 +2,39 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"betting-odds-scraper/internal/analysis"
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/normalize"
+	"betting-odds-scraper/internal/scheduler"
 	"betting-odds-scraper/internal/scraper"
 
 	"github.com/gin-gonic/gin"
 )
 
+// shutdownTimeout is how long requests in flight get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 type Server struct {
-	router  *gin.Engine
-	manager *scraper.Manager
+	router    *gin.Engine
+	manager   *scraper.Manager
+	scheduler *scheduler.Scheduler
 }
 
-func NewServer(manager *scraper.Manager) *Server {
+func NewServer(manager *scraper.Manager, scheduler *scheduler.Scheduler) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.Default()
 
 	server := &Server{
-		router:  router,
-		manager: manager,
+		router:    router,
+		manager:   manager,
+		scheduler: scheduler,
 	}
 
 	server.setupRoutes()
@@ -32,14 +45,14 @@ func (s *Server) setupRoutes() {
 	// CORS middleware
 	s.router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type")
-		
+
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
 		}
-		
+
 		c.Next()
 	})
 
@@ -49,32 +62,252 @@ func (s *Server) setupRoutes() {
 		api.GET("/health", s.healthCheck)
 		api.GET("/odds/best", s.getBestOdds)
 		api.GET("/odds/stats", s.getOddsStats)
+		api.GET("/arbitrage", s.getArbitrage)
+		api.GET("/matches/:id/history", s.getMatchHistory)
+		api.GET("/margins", s.getMargins)
+		api.GET("/margins/leagues", s.getLeagueMargins)
 		api.GET("/scrape/results", s.getScrapeResults)
 		api.POST("/scrape/trigger", s.triggerScrape)
+		api.GET("/scrape/jobs", s.getScrapeJobs)
+		api.GET("/scrape/jobs/:id", s.getScrapeJob)
 		api.GET("/sites", s.getSites)
 		api.GET("/sites/status", s.getSitesStatus)
+		api.GET("/alerts", s.getAlerts)
+		api.GET("/normalize/review", s.getReviewQueue)
+		api.POST("/normalize/review/:id/confirm", s.confirmReview)
+		api.POST("/normalize/review/:id/reject", s.rejectReview)
+		api.GET("/normalize/decisions", s.getDecisions)
+	}
+
+	// Admin routes
+	admin := s.router.Group("/api/v1/admin")
+	{
+		admin.GET("/schedules", s.getSchedules)
+		admin.PUT("/schedules/:site", s.updateSchedule)
+		admin.GET("/queue", s.getQueue)
+		admin.GET("/rate-limits", s.getRateLimits)
+		admin.GET("/proxies", s.getProxies)
 	}
 
 	// Serve static files for simple web interface
 	s.router.Static("/static", "./web/static")
 	s.router.LoadHTMLGlob("web/templates/*")
 	s.router.GET("/", s.indexPage)
+	s.router.GET("/metrics", s.metrics)
 }
 
 func (s *Server) healthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	health := gin.H{
 		"status":    "healthy",
 		"timestamp": time.Now(),
 		"service":   "betting-odds-scraper",
-	})
+	}
+	if stats := s.manager.BrowserStats(); stats != nil {
+		health["browsers"] = stats
+	}
+	if proxies := s.manager.Proxies(); len(proxies) > 0 {
+		usable := 0
+		for _, proxy := range proxies {
+			if proxy.Healthy && !proxy.Evicted {
+				usable++
+			}
+		}
+		health["proxies"] = gin.H{"total": len(proxies), "usable": usable}
+	}
+	c.JSON(http.StatusOK, health)
 }
 
 func (s *Server) getBestOdds(c *gin.Context) {
-	bestOdds := s.manager.GetBestOdds()
+	bestOdds, ok := s.bestOdds(c)
+	if !ok {
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    bestOdds,
 		"count":   len(bestOdds),
+		"net":     netMode(c),
+	})
+}
+
+// bestOdds returns best odds ranked on net prices when ?net=true is set and
+// built from the provenances ?provenance= asks for. It answers bad requests
+// itself, returning false.
+func (s *Server) bestOdds(c *gin.Context) ([]models.BestOdds, bool) {
+	provenances, ok := provenanceFilter(c)
+	if !ok {
+		return nil, false
+	}
+	return s.manager.GetBestOddsFrom(netMode(c), provenances), true
+}
+
+// provenanceFilter reads the comma-separated ?provenance= list, answering an
+// unknown provenance with a bad request and false
+func provenanceFilter(c *gin.Context) ([]string, bool) {
+	var provenances []string
+	for _, provenance := range strings.Split(c.Query("provenance"), ",") {
+		provenance = strings.TrimSpace(provenance)
+		if provenance == "" {
+			continue
+		}
+		if !slices.Contains(models.Provenances, provenance) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "provenance must be one of " + strings.Join(models.Provenances, ", ")})
+			return nil, false
+		}
+		provenances = append(provenances, provenance)
+	}
+	return provenances, true
+}
+
+// netMode reports whether the request asked for after-tax net prices
+func netMode(c *gin.Context) bool {
+	net, _ := strconv.ParseBool(c.Query("net"))
+	return net
+}
+
+func (s *Server) getArbitrage(c *gin.Context) {
+	filter := analysis.ArbitrageFilter{
+		Sport: c.Query("sport"),
+	}
+	provenances, ok := provenanceFilter(c)
+	if !ok {
+		return
+	}
+
+	var err error
+	if value := c.Query("min_margin"); value != "" {
+		if filter.MinProfitPercent, err = strconv.ParseFloat(value, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid min_margin"})
+			return
+		}
+	}
+	if value := c.Query("from"); value != "" {
+		if filter.KickoffFrom, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid from, expected RFC3339"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.KickoffTo, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid to, expected RFC3339"})
+			return
+		}
+	}
+	if value := c.Query("within_hours"); value != "" {
+		// Both bound the latest kickoff, so giving both is ambiguous
+		if c.Query("to") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "within_hours and to cannot be combined"})
+			return
+		}
+		hours, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid within_hours"})
+			return
+		}
+		filter.KickoffTo = time.Now().Add(time.Duration(hours * float64(time.Hour)))
+	}
+
+	arbitrage := analysis.FilterArbitrage(s.manager.GetArbitrageFrom(netMode(c), provenances), filter)
+
+	if value := c.Query("stake"); value != "" {
+		stake, err := strconv.ParseFloat(value, 64)
+		if err != nil || stake <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid stake"})
+			return
+		}
+		for i := range arbitrage {
+			arbitrage[i] = analysis.SplitStake(arbitrage[i], stake)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    arbitrage,
+		"count":   len(arbitrage),
+	})
+}
+
+func (s *Server) getMatchHistory(c *gin.Context) {
+	history, exists, err := s.manager.GetMatchHistory(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "match not found"})
+		return
+	}
+
+	// Optionally narrow to one market, selection or site
+	market, selection, siteID := c.Query("market"), c.Query("selection"), c.Query("site")
+	movements := history.Movements[:0]
+	for _, movement := range history.Movements {
+		switch {
+		case market != "" && movement.Market != market:
+			continue
+		case selection != "" && movement.Selection != selection:
+			continue
+		case siteID != "" && movement.SiteID != siteID:
+			continue
+		}
+		movements = append(movements, movement)
+	}
+	history.Movements = movements
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    history,
+		"count":   len(history.Movements),
+	})
+}
+
+func (s *Server) getMargins(c *gin.Context) {
+	method := c.DefaultQuery("method", analysis.DevigProportional)
+	if !analysis.ValidDevigMethod(method) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "method must be proportional, shin or power"})
+		return
+	}
+
+	provenances, ok := provenanceFilter(c)
+	if !ok {
+		return
+	}
+
+	bestOdds := s.manager.GetBestOddsFrom(false, provenances)
+	if matchID := c.Query("match_id"); matchID != "" {
+		filtered := bestOdds[:0]
+		for _, fixture := range bestOdds {
+			if fixture.Match.ID == matchID {
+				filtered = append(filtered, fixture)
+			}
+		}
+		bestOdds = filtered
+	}
+
+	margins, err := analysis.SiteMarginsFor(bestOdds, method)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    margins,
+		"count":   len(margins),
+		"method":  method,
+	})
+}
+
+func (s *Server) getLeagueMargins(c *gin.Context) {
+	provenances, ok := provenanceFilter(c)
+	if !ok {
+		return
+	}
+	table := analysis.LeagueMarginTable(s.manager.GetBestOddsFrom(false, provenances), c.Query("market"))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    table,
+		"count":   len(table),
 	})
 }
 
@@ -87,27 +320,73 @@ func (s *Server) getScrapeResults(c *gin.Context) {
 }
 
 func (s *Server) triggerScrape(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	// Sites come from ?sites=a,b or a {"sites": [...]} body; none means all
+	var body struct {
+		Sites []string `json:"sites"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+	}
+	if value := c.Query("sites"); value != "" {
+		body.Sites = append(body.Sites, strings.Split(value, ",")...)
+	}
+
+	job, running, err := s.scheduler.Trigger(body.Sites)
+	if errors.Is(err, scheduler.ErrRefreshing) {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	// Every site is already being scraped, by one or more jobs
+	if running != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"success":      true,
+			"message":      "Scrape already running",
+			"deduplicated": true,
+			"data":         running,
+			"count":        len(running),
+		})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":      true,
+		"message":      "Scrape job started",
+		"deduplicated": false,
+		"data":         job,
+	})
+}
 
-	results := s.manager.ScrapeAll(ctx)
-	
+func (s *Server) getScrapeJobs(c *gin.Context) {
+	jobs := s.scheduler.Jobs()
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Scraping completed",
-		"results": results,
+		"data":    jobs,
+		"count":   len(jobs),
 	})
 }
 
-func (s *Server) getSites(c *gin.Context) {
-	// This would return information about supported betting sites
-	sites := []map[string]interface{}{
-		{"id": "betika", "name": "Betika", "active": true},
-		{"id": "sportpesa", "name": "SportPesa", "active": true},
-		{"id": "betway", "name": "Betway", "active": true},
-		{"id": "odibets", "name": "Odibets", "active": true},
+func (s *Server) getScrapeJob(c *gin.Context) {
+	job, exists := s.scheduler.Job(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "job not found"})
+		return
 	}
-	
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+func (s *Server) getSites(c *gin.Context) {
+	sites := s.manager.GetSites()
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    sites,
@@ -115,90 +394,284 @@ func (s *Server) getSites(c *gin.Context) {
 }
 
 func (s *Server) getOddsStats(c *gin.Context) {
-	bestOdds := s.manager.GetBestOdds()
-	
+	bestOdds, ok := s.bestOdds(c)
+	if !ok {
+		return
+	}
+	net := netMode(c)
+
 	totalMatches := len(bestOdds)
-	totalSites := 4 // Betika, SportPesa, Betway, Odibets
-	
+	totalSites := len(s.manager.GetSites())
+
 	// Calculate average odds and other stats
 	var totalHomeOdds, totalDrawOdds, totalAwayOdds float64
 	var homeCount, drawCount, awayCount int
-	
+
 	for _, match := range bestOdds {
 		if match.BestHomeWin != nil {
-			totalHomeOdds += match.BestHomeWin.Value
+			totalHomeOdds += comparisonValue(match.BestHomeWin, net)
 			homeCount++
 		}
 		if match.BestDraw != nil {
-			totalDrawOdds += match.BestDraw.Value
+			totalDrawOdds += comparisonValue(match.BestDraw, net)
 			drawCount++
 		}
 		if match.BestAwayWin != nil {
-			totalAwayOdds += match.BestAwayWin.Value
+			totalAwayOdds += comparisonValue(match.BestAwayWin, net)
 			awayCount++
 		}
 	}
-	
+
 	stats := gin.H{
 		"total_matches": totalMatches,
-		"total_sites": totalSites,
+		"total_sites":   totalSites,
 		"average_home_odds": func() float64 {
-			if homeCount > 0 { return totalHomeOdds / float64(homeCount) }
+			if homeCount > 0 {
+				return totalHomeOdds / float64(homeCount)
+			}
 			return 0
 		}(),
 		"average_draw_odds": func() float64 {
-			if drawCount > 0 { return totalDrawOdds / float64(drawCount) }
+			if drawCount > 0 {
+				return totalDrawOdds / float64(drawCount)
+			}
 			return 0
 		}(),
 		"average_away_odds": func() float64 {
-			if awayCount > 0 { return totalAwayOdds / float64(awayCount) }
+			if awayCount > 0 {
+				return totalAwayOdds / float64(awayCount)
+			}
 			return 0
 		}(),
-		"last_updated": time.Now(),
+		"average_margin_by_site": analysis.SiteAverageMargins(bestOdds),
+		"net":                    net,
+		"last_updated":           time.Now(),
 	}
-	
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": stats,
+		"data":    stats,
 	})
 }
 
+// comparisonValue picks the net or raw price of a best-odds comparison
+func comparisonValue(comparison *models.OddsComparison, net bool) float64 {
+	if net {
+		return comparison.NetValue
+	}
+	return comparison.Value
+}
+
 func (s *Server) getSitesStatus(c *gin.Context) {
 	results := s.manager.GetScrapeResults()
-	
-	sites := []gin.H{
-		{"id": "betika", "name": "Betika", "url": "https://www.betika.com"},
-		{"id": "sportpesa", "name": "SportPesa", "url": "https://www.sportpesa.com"},
-		{"id": "betway", "name": "Betway", "url": "https://www.betway.co.ke"},
-		{"id": "odibets", "name": "Odibets", "url": "https://www.odibets.com"},
-	}
-	
-	// Add status information from latest scrape results
-	for i, site := range sites {
-		siteID := site["id"].(string)
-		if siteResults, exists := results[siteID]; exists && len(siteResults) > 0 {
+
+	// Failures by error kind over each site's recent scrapes, and in total
+	totals := make(map[string]int)
+	var sites []gin.H
+	for _, site := range s.manager.GetSites() {
+		errorKinds := make(map[string]int)
+		for _, result := range results[site.ID] {
+			if result.ErrorKind != "" {
+				errorKinds[result.ErrorKind]++
+				totals[result.ErrorKind]++
+			}
+		}
+
+		status := gin.H{
+			"active":      false,
+			"state":       "unknown",
+			"health":      0,
+			"last_scrape": nil,
+			"match_count": 0,
+			"odds_count":  0,
+			"error":       "No data available",
+		}
+
+		// Add status information from the latest scrape result
+		if siteResults := results[site.ID]; len(siteResults) > 0 {
 			latest := siteResults[len(siteResults)-1]
-			sites[i]["status"] = map[string]interface{}{
-				"active": latest.Success,
+			status = gin.H{
+				"active":      latest.Success,
+				"state":       latest.State(),
+				"health":      latest.Health,
 				"last_scrape": latest.ScrapedAt,
 				"match_count": latest.MatchCount,
-				"odds_count": latest.OddsCount,
-				"error": latest.Error,
-			}
-		} else {
-			sites[i]["status"] = map[string]interface{}{
-				"active": false,
-				"last_scrape": nil,
-				"match_count": 0,
-				"odds_count": 0,
-				"error": "No data available",
+				"odds_count":  latest.OddsCount,
+				"error":       latest.Error,
+				"error_kind":  latest.ErrorKind,
+				"issues":      latest.Issues,
+				"checks":      latest.Checks,
 			}
 		}
+		sites = append(sites, gin.H{
+			"id":      site.ID,
+			"name":    site.Name,
+			"url":     site.URL,
+			"status":  status,
+			"errors":  errorKinds,
+			"breaker": s.manager.BreakerStatus(site.ID),
+		})
 	}
-	
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sites,
+		"errors":  totals,
+	})
+}
+
+func (s *Server) getAlerts(c *gin.Context) {
+	alerts := s.manager.GetAlerts()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alerts,
+		"count":   len(alerts),
+	})
+}
+
+func (s *Server) getReviewQueue(c *gin.Context) {
+	queue := s.manager.Normalizer().ReviewQueue()
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": sites,
+		"data":    queue,
+		"count":   len(queue),
+	})
+}
+
+func (s *Server) confirmReview(c *gin.Context) {
+	// The operator may supply a different canonical name than the suggestion
+	var body struct {
+		Canonical string `json:"canonical"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+	}
+
+	decision, err := s.manager.Normalizer().Confirm(c.Param("id"), body.Canonical)
+	s.respondDecision(c, decision, err)
+}
+
+func (s *Server) rejectReview(c *gin.Context) {
+	decision, err := s.manager.Normalizer().Reject(c.Param("id"))
+	s.respondDecision(c, decision, err)
+}
+
+func (s *Server) respondDecision(c *gin.Context, decision normalize.Decision, err error) {
+	if errors.Is(err, normalize.ErrCandidateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    decision,
+	})
+}
+
+func (s *Server) getDecisions(c *gin.Context) {
+	decisions := s.manager.Normalizer().Decisions()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    decisions,
+		"count":   len(decisions),
+	})
+}
+
+func (s *Server) getSchedules(c *gin.Context) {
+	schedules := s.scheduler.Schedules()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    schedules,
+		"count":   len(schedules),
+	})
+}
+
+// getRateLimits lists each bookmaker domain's request budget and how much
+// waiting it has caused
+func (s *Server) getRateLimits(c *gin.Context) {
+	domains := s.manager.RateLimits()
+	limits := make([]gin.H, 0, len(domains))
+	for _, domain := range domains {
+		limits = append(limits, gin.H{
+			"domain":              domain.Domain,
+			"requests_per_window": domain.Policy.Requests,
+			"window_seconds":      domain.Policy.Window.Seconds(),
+			"burst":               domain.Policy.Burst,
+			"crawl_delay_seconds": domain.Policy.CrawlDelay.Seconds(),
+			"requests":            domain.Requests,
+			"waited_seconds":      domain.Waited.Seconds(),
+			"robots_disallowed":   domain.Disallowed,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    limits,
+		"count":   len(limits),
+	})
+}
+
+// getProxies lists the proxy pool's health and bans, and the identity each
+// site is currently using
+func (s *Server) getProxies(c *gin.Context) {
+	proxies := s.manager.Proxies()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"proxies": proxies,
+			"sites":   s.manager.Identities(),
+		},
+		"count": len(proxies),
+	})
+}
+
+func (s *Server) updateSchedule(c *gin.Context) {
+	// A zero or missing interval restores the configured default
+	var body struct {
+		IntervalSeconds int `json:"interval_seconds"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if body.IntervalSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid interval_seconds"})
+		return
+	}
+
+	schedule, err := s.scheduler.SetSchedule(c.Param("site"), time.Duration(body.IntervalSeconds)*time.Second)
+	if errors.Is(err, scheduler.ErrUnknownSite) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    schedule,
+	})
+}
+
+func (s *Server) getQueue(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid limit"})
+		return
+	}
+
+	queue := s.scheduler.Queue(limit)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    queue,
+		"count":   len(queue),
 	})
 }
 
@@ -208,6 +681,22 @@ func (s *Server) indexPage(c *gin.Context) {
 	})
 }
 
-func (s *Server) Run(addr string) error {
-	return s.router.Run(addr)
-}
\ No newline at end of file
+// Run serves the API on addr until ctx is done, then stops accepting
+// connections and waits for requests in flight to finish
+func (s *Server) Run(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: s.router}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
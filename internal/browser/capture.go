@@ -0,0 +1,179 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// captureTick is how often a capture checks whether the page has settled
+const captureTick = 100 * time.Millisecond
+
+// Response is a JSON response captured while a page loaded
+type Response struct {
+	URL        string
+	Body       []byte
+	CapturedAt time.Time
+}
+
+// Capture collects the JSON responses a tab receives from URLs matching a
+// set of patterns, so scrapers can read the data a page renders from instead
+// of its HTML
+type Capture struct {
+	patterns []*regexp.Regexp
+	dir      string
+
+	mutex     sync.Mutex
+	pending   map[network.RequestID]string
+	fetching  int
+	responses []Response
+	lastEvent time.Time
+	loadedAt  time.Time
+}
+
+// Capture starts capturing the tab's responses from URLs matching the
+// pattern configured for siteID, or defaults when none is configured.
+// Captures are also saved to disk when a capture directory is configured.
+func (t *Tab) Capture(siteID string, defaults []*regexp.Regexp) *Capture {
+	patterns := defaults
+	if configured, exists := t.pool.capturePatterns[siteID]; exists {
+		patterns = []*regexp.Regexp{configured}
+	}
+
+	c := &Capture{
+		patterns:  patterns,
+		pending:   make(map[network.RequestID]string),
+		lastEvent: time.Now(),
+	}
+	if t.pool.captureDir != "" {
+		c.dir = filepath.Join(t.pool.captureDir, siteID)
+	}
+
+	ctx := t.ctx
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *network.EventResponseReceived:
+			if c.matches(ev.Response.URL) {
+				c.mutex.Lock()
+				c.pending[ev.RequestID] = ev.Response.URL
+				c.lastEvent = time.Now()
+				c.mutex.Unlock()
+			}
+		case *network.EventLoadingFinished:
+			c.mutex.Lock()
+			url, exists := c.pending[ev.RequestID]
+			delete(c.pending, ev.RequestID)
+			if exists {
+				c.fetching++
+			}
+			c.mutex.Unlock()
+			// Bodies cannot be read from within the event listener
+			if exists {
+				go c.fetch(ctx, ev.RequestID, url)
+			}
+		case *network.EventLoadingFailed:
+			c.mutex.Lock()
+			delete(c.pending, ev.RequestID)
+			c.mutex.Unlock()
+		case *page.EventLoadEventFired:
+			c.mutex.Lock()
+			c.loadedAt = time.Now()
+			c.mutex.Unlock()
+		}
+	})
+	return c
+}
+
+func (c *Capture) matches(url string) bool {
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Capture) fetch(ctx context.Context, id network.RequestID, url string) {
+	body, err := network.GetResponseBody(id).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fetching--
+	c.lastEvent = time.Now()
+
+	switch {
+	case err != nil:
+		log.Printf("Failed to read captured response %s: %v", url, err)
+		return
+	case !json.Valid(body):
+		return
+	}
+
+	response := Response{URL: url, Body: body, CapturedAt: time.Now()}
+	c.responses = append(c.responses, response)
+	if c.dir != "" {
+		c.save(response, len(c.responses))
+	}
+}
+
+// save writes a captured body to the capture directory for debugging and
+// for turning into test fixtures
+func (c *Capture) save(response Response, n int) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		log.Printf("Failed to create capture directory: %v", err)
+		return
+	}
+	name := fmt.Sprintf("%s_%02d.json", response.CapturedAt.Format("20060102T150405"), n)
+	if err := os.WriteFile(filepath.Join(c.dir, name), response.Body, 0644); err != nil {
+		log.Printf("Failed to save capture of %s: %v", response.URL, err)
+	}
+}
+
+// Wait returns an action that waits until at least one matching response has
+// been captured and no further matching request has started or finished for
+// quiet. When nothing has matched it waits only until quiet after the page
+// load. It gives up without error after max, leaving the caller to fall back
+// to the page HTML.
+func (c *Capture) Wait(max, quiet time.Duration) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		deadline := time.Now().Add(max)
+		ticker := time.NewTicker(captureTick)
+		defer ticker.Stop()
+
+		for {
+			c.mutex.Lock()
+			idle := len(c.pending) == 0 && c.fetching == 0 && time.Since(c.lastEvent) >= quiet
+			loaded := !c.loadedAt.IsZero() && time.Since(c.loadedAt) >= quiet
+			settled := idle && (len(c.responses) > 0 || loaded)
+			c.mutex.Unlock()
+			if settled || time.Now().After(deadline) {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Responses returns the responses captured so far, in the order they arrived
+func (c *Capture) Responses() []Response {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]Response(nil), c.responses...)
+}
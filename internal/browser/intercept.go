@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"context"
+
+	"betting-odds-scraper/internal/proxy"
+	"betting-odds-scraper/internal/ratelimit"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// throttledTypes are the requests a tab holds back for the rate limiter:
+// navigations and the page's own data calls, but not its scripts, styles and
+// images, which a browser fetches many of at once
+var throttledTypes = map[network.ResourceType]bool{
+	network.ResourceTypeDocument: true,
+	network.ResourceTypeXHR:      true,
+	network.ResourceTypeFetch:    true,
+}
+
+// proxyServer routes a new browser context through the identity's proxy
+func proxyServer(identity proxy.Identity) []chromedp.CreateBrowserContextOption {
+	if identity.Proxy == nil {
+		return nil
+	}
+	server := identity.Proxy.Server()
+	return []chromedp.CreateBrowserContextOption{
+		func(params *target.CreateBrowserContextParams) *target.CreateBrowserContextParams {
+			return params.WithProxyServer(server)
+		},
+	}
+}
+
+// prepare applies the scrape's identity and rate limiter found on ctx to the
+// tab: its profile's user agent and window size, and request interception
+// for rate limiting and proxy authentication
+func (t *Tab) prepare(ctx context.Context) error {
+	limiter := ratelimit.FromContext(ctx)
+	identity, _ := proxy.FromContext(ctx)
+
+	var actions []chromedp.Action
+	if profile := identity.Profile; profile.UserAgent != "" {
+		actions = append(actions,
+			emulation.SetUserAgentOverride(profile.UserAgent).
+				WithAcceptLanguage(profile.AcceptLanguage).
+				WithPlatform(profile.Platform),
+			chromedp.EmulateViewport(profile.Width, profile.Height),
+		)
+	}
+	if intercept := t.intercept(limiter, identity.Proxy); intercept != nil {
+		actions = append(actions, intercept)
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+	return chromedp.Run(t.ctx, actions...)
+}
+
+// intercept pauses the tab's requests so that navigations and XHR calls wait
+// for limiter, sharing their domain's budget with every other scraper, and so
+// that the proxy's credentials can be given when it asks. Requests the
+// limiter refuses fail as blocked by the client. It returns nil when there is
+// nothing to intercept.
+func (t *Tab) intercept(limiter *ratelimit.Limiter, via *proxy.Proxy) chromedp.Action {
+	var username, password string
+	auth := false
+	if via != nil {
+		username, password, auth = via.Credentials()
+	}
+	if limiter == nil && !auth {
+		return nil
+	}
+
+	ctx := t.ctx
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		// Commands cannot be sent from within the event listener
+		switch ev := ev.(type) {
+		case *fetch.EventRequestPaused:
+			go func() {
+				executor := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+				if throttledTypes[ev.ResourceType] {
+					if err := limiter.Wait(ctx, ev.Request.URL); err != nil {
+						fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient).Do(executor)
+						return
+					}
+				}
+				fetch.ContinueRequest(ev.RequestID).Do(executor)
+			}()
+		case *fetch.EventAuthRequired:
+			go func() {
+				executor := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+				response := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+				if ev.AuthChallenge.Source == fetch.AuthChallengeSourceProxy {
+					response = &fetch.AuthChallengeResponse{
+						Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+						Username: username,
+						Password: password,
+					}
+				}
+				fetch.ContinueWithAuth(ev.RequestID, response).Do(executor)
+			}()
+		}
+	})
+
+	// Proxy challenges only reach paused requests, so authenticating proxies
+	// need every request paused
+	var patterns []*fetch.RequestPattern
+	if auth {
+		patterns = []*fetch.RequestPattern{{URLPattern: "*"}}
+	} else {
+		for resourceType := range throttledTypes {
+			patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*", ResourceType: resourceType})
+		}
+	}
+	return fetch.Enable().WithPatterns(patterns).WithHandleAuthRequests(auth)
+}
@@ -0,0 +1,281 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+	"betting-odds-scraper/internal/proxy"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrPoolClosed is returned when borrowing a tab from a closed pool
+var ErrPoolClosed = errors.New("browser pool closed")
+
+// healthCheckTimeout bounds the probe run on a browser after a failed tab
+const healthCheckTimeout = 5 * time.Second
+
+// Pool keeps a bounded number of long-lived Chrome instances and lends out
+// isolated tabs on them. Browsers are launched on first use, and recycled
+// after a number of uses or when they stop responding.
+type Pool struct {
+	opts    []chromedp.ExecAllocatorOption
+	maxUses int
+	slots   chan *instance
+
+	// Network capture settings shared by every tab
+	capturePatterns map[string]*regexp.Regexp
+	captureDir      string
+
+	closed chan struct{}
+	once   sync.Once
+
+	mutex    sync.Mutex
+	launched int
+	recycled int
+	all      map[*instance]bool
+}
+
+// instance is one running Chrome process
+type instance struct {
+	allocCancel   context.CancelFunc
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+	uses          int
+}
+
+// Tab is an isolated browser tab borrowed from the pool. It has its own
+// browser context, so cookies and storage are not shared between scrapes.
+type Tab struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   func() bool
+	inst   *instance
+	pool   *Pool
+}
+
+// Stats describes the pool for status reporting
+type Stats struct {
+	Size     int `json:"size"`
+	Running  int `json:"running"`
+	Launched int `json:"launched"`
+	Recycled int `json:"recycled"`
+	MaxUses  int `json:"max_uses"`
+}
+
+// NewPool creates a pool of up to size browsers configured from cfg
+func NewPool(cfg *config.Config) *Pool {
+	size := cfg.BrowserPoolSize
+	if size < 1 {
+		size = 1
+	}
+
+	p := &Pool{
+		opts:            allocatorOptions(cfg),
+		maxUses:         cfg.BrowserMaxUses,
+		capturePatterns: cfg.CapturePatterns,
+		captureDir:      cfg.CaptureDir,
+		slots:           make(chan *instance, size),
+		closed:          make(chan struct{}),
+		all:             make(map[*instance]bool),
+	}
+	// Empty slots are filled with a browser when first borrowed
+	for i := 0; i < size; i++ {
+		p.slots <- nil
+	}
+	return p
+}
+
+// allocatorOptions builds the Chrome flags shared by every browser
+func allocatorOptions(cfg *config.Config) []chromedp.ExecAllocatorOption {
+	return append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", cfg.ChromeHeadless),
+		chromedp.Flag("disable-gpu", cfg.ChromeDisableGPU),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-logging", true),
+		chromedp.Flag("disable-extensions", true),
+		chromedp.Flag("log-level", "3"), // Suppress INFO, WARNING, ERROR
+	)
+}
+
+// NewTab borrows a browser, waiting for one to become free, and opens a tab
+// on it. The tab is closed when ctx is done or the tab is released.
+func (p *Pool) NewTab(ctx context.Context) (*Tab, error) {
+	var inst *instance
+	select {
+	case inst = <-p.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.closed:
+		return nil, ErrPoolClosed
+	}
+	// A free slot and a closed pool can be ready together
+	select {
+	case <-p.closed:
+		p.slots <- inst
+		return nil, ErrPoolClosed
+	default:
+	}
+
+	if inst == nil || inst.browserCtx.Err() != nil {
+		p.discard(inst)
+		var err error
+		if inst, err = p.launch(); err != nil {
+			p.slots <- nil
+			return nil, err
+		}
+	}
+
+	identity, _ := proxy.FromContext(ctx)
+	tabCtx, cancel := chromedp.NewContext(inst.browserCtx, chromedp.WithNewBrowserContext(proxyServer(identity)...))
+	tab := &Tab{
+		ctx:    tabCtx,
+		cancel: cancel,
+		stop:   context.AfterFunc(ctx, cancel),
+		inst:   inst,
+		pool:   p,
+	}
+	// The tab presents the scrape's identity, and its navigations and XHR
+	// calls share their domain's request budget
+	if err := tab.prepare(ctx); err != nil {
+		tab.Release(err)
+		return nil, err
+	}
+	return tab, nil
+}
+
+// Context is the tab's chromedp context
+func (t *Tab) Context() context.Context {
+	return t.ctx
+}
+
+// Release closes the tab and returns its browser to the pool. A non-nil err
+// from the tab's work prompts a health check, and a browser that no longer
+// responds is recycled, as is one that has reached its maximum uses.
+func (t *Tab) Release(err error) {
+	t.stop()
+	t.cancel()
+
+	inst := t.inst
+	inst.uses++
+
+	switch {
+	case inst.browserCtx.Err() != nil:
+		log.Printf("Browser crashed, recycling")
+		t.pool.recycle(inst)
+	case err != nil && !t.pool.healthy(inst):
+		log.Printf("Browser stopped responding after error (%v), recycling", err)
+		t.pool.recycle(inst)
+	case t.pool.maxUses > 0 && inst.uses >= t.pool.maxUses:
+		t.pool.recycle(inst)
+	default:
+		t.pool.slots <- inst
+	}
+}
+
+// Stats returns the pool's current size and lifetime counters
+func (p *Pool) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return Stats{
+		Size:     cap(p.slots),
+		Running:  len(p.all),
+		Launched: p.launched,
+		Recycled: p.recycled,
+		MaxUses:  p.maxUses,
+	}
+}
+
+// Close shuts down every browser. Tabs still in use are closed with them.
+func (p *Pool) Close() {
+	p.once.Do(func() {
+		close(p.closed)
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		for inst := range p.all {
+			inst.browserCancel()
+			inst.allocCancel()
+		}
+		p.all = make(map[*instance]bool)
+	})
+}
+
+func (p *Pool) launch() (*instance, error) {
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), p.opts...)
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+
+	// Running with no actions starts the browser process
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, err
+	}
+
+	inst := &instance{
+		allocCancel:   allocCancel,
+		browserCtx:    browserCtx,
+		browserCancel: browserCancel,
+	}
+	if err := p.adopt(inst); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// adopt adds a launched browser to the pool. A browser that finished
+// launching after the pool was closed is shut down instead of leaked.
+func (p *Pool) adopt(inst *instance) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	select {
+	case <-p.closed:
+		inst.browserCancel()
+		inst.allocCancel()
+		return ErrPoolClosed
+	default:
+	}
+	p.all[inst] = true
+	p.launched++
+	return nil
+}
+
+// healthy probes a browser by listing its targets
+func (p *Pool) healthy(inst *instance) bool {
+	ctx, cancel := context.WithTimeout(inst.browserCtx, healthCheckTimeout)
+	defer cancel()
+
+	_, err := chromedp.Targets(ctx)
+	return err == nil
+}
+
+// recycle shuts a browser down and frees its slot for a fresh one
+func (p *Pool) recycle(inst *instance) {
+	p.discard(inst)
+
+	p.mutex.Lock()
+	p.recycled++
+	p.mutex.Unlock()
+
+	p.slots <- nil
+}
+
+func (p *Pool) discard(inst *instance) {
+	if inst == nil {
+		return
+	}
+	inst.browserCancel()
+	inst.allocCancel()
+
+	p.mutex.Lock()
+	delete(p.all, inst)
+	p.mutex.Unlock()
+}
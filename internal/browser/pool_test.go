@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+
+	"github.com/chromedp/chromedp"
+)
+
+// testPool creates a pool whose browsers fail to launch, so it can be tested
+// without Chrome
+func testPool(size int) *Pool {
+	p := NewPool(&config.Config{BrowserPoolSize: size, BrowserMaxUses: 10})
+	p.opts = append(p.opts, chromedp.ExecPath("/nonexistent/chrome"))
+	return p
+}
+
+// fakeInstance is a browser that was never started
+func fakeInstance() *instance {
+	allocCtx, allocCancel := context.WithCancel(context.Background())
+	browserCtx, browserCancel := context.WithCancel(allocCtx)
+	return &instance{allocCancel: allocCancel, browserCtx: browserCtx, browserCancel: browserCancel}
+}
+
+func TestPoolLaunchFailure(t *testing.T) {
+	p := testPool(1)
+	defer p.Close()
+
+	// A failed launch frees its slot, so the next tab tries again rather
+	// than waiting forever
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := p.NewTab(ctx)
+		cancel()
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("NewTab %d = %v, want a launch error", i, err)
+		}
+	}
+	if stats := p.Stats(); stats != (Stats{Size: 1, MaxUses: 10}) {
+		t.Errorf("stats %+v, want no browsers", stats)
+	}
+}
+
+func TestPoolWaitsForSlot(t *testing.T) {
+	p := testPool(1)
+	defer p.Close()
+
+	<-p.slots
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.NewTab(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NewTab with every browser in use = %v, want the context's error", err)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	p := testPool(2)
+	p.Close()
+	p.Close()
+
+	// Free slots are ready too, but a closed pool lends nothing
+	for i := 0; i < 10; i++ {
+		if _, err := p.NewTab(context.Background()); !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("NewTab on a closed pool = %v, want ErrPoolClosed", err)
+		}
+	}
+}
+
+func TestPoolAdopt(t *testing.T) {
+	p := testPool(2)
+
+	running := fakeInstance()
+	if err := p.adopt(running); err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.Stats(); stats.Running != 1 || stats.Launched != 1 {
+		t.Errorf("stats %+v, want one browser running", stats)
+	}
+
+	p.Close()
+	if running.browserCtx.Err() == nil {
+		t.Error("Close left a browser running")
+	}
+
+	// A browser that finishes launching after Close is shut down
+	late := fakeInstance()
+	if err := p.adopt(late); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("adopt after Close = %v, want ErrPoolClosed", err)
+	}
+	if late.browserCtx.Err() == nil {
+		t.Error("a browser launched after Close was left running")
+	}
+	if stats := p.Stats(); stats.Running != 0 || stats.Launched != 1 {
+		t.Errorf("stats %+v, want no browsers running", stats)
+	}
+}
@@ -1,35 +1,184 @@
 package config
 
 import (
+	"log"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Modes choose where odds come from: bookmakers, a simulation, or recorded
+// scrapes
+const (
+	ModeLive   = "live"
+	ModeDemo   = "demo"
+	ModeReplay = "replay"
+)
+
 type Config struct {
-	Port                string
-	ScrapeInterval      time.Duration
+	Mode                  string
+	DemoSeed              int64
+	Port                  string
+	ScrapeInterval        time.Duration
+	SiteSchedules         map[string]time.Duration
+	ScrapeJitter          time.Duration
+	AdaptiveScheduling    bool
 	MaxConcurrentScrapers int
-	RequestTimeout      time.Duration
-	ChromeHeadless      bool
-	ChromeDisableGPU    bool
-	RateLimitRequests   int
-	RateLimitWindow     time.Duration
-	LogLevel            string
+	RequestTimeout        time.Duration
+	ChromeHeadless        bool
+	ChromeDisableGPU      bool
+	BrowserPoolSize       int
+	BrowserMaxUses        int
+	RateLimitRequests     int
+	RateLimitWindow       time.Duration
+	RateLimitBurst        int
+	CrawlDelay            time.Duration
+	SiteRateLimits        map[string]RateLimit
+	RespectRobotsTxt      bool
+	ProxyFile             string
+	ProxyCheckURL         string
+	ProxyCheckInterval    time.Duration
+	ProxyBanThreshold     int
+	ProxyBanCooldown      time.Duration
+	ProxyMaxBans          int
+	RotateIdentities      bool
+	LogLevel              string
+	MatchKickoffWindow    time.Duration
+	AliasFile             string
+	AliasDecisionsFile    string
+	FuzzyMatchThreshold   float64
+	FuzzyReviewThreshold  float64
+	ArbitrageMaxOddsAge   time.Duration
+	ArbitrageStake        float64
+	TaxProfilesFile       string
+	StoreDriver           string
+	DatabasePath          string
+	SitesDir              string
+	SiteAPIs              bool
+	CapturePatterns       map[string]*regexp.Regexp
+	CaptureDir            string
+	FixturesMode          string
+	FixturesDir           string
+	SiteBaseURLs          map[string]string
+	HealthMinEvents       int
+	HealthMinParsed       float64
+	HealthMinKnownLeagues float64
+	HealthMaxDrop         float64
+	AlertWebhookURL       string
+	ScrapeRetries         int
+	RetryBaseDelay        time.Duration
+	RetryMaxDelay         time.Duration
+	RetryJitter           float64
+	BreakerThreshold      int
+	BreakerCooldown       time.Duration
+}
+
+// RateLimit allows Requests requests to a site per Window
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
 }
 
 func New() *Config {
+	logLevel := getEnv("LOG_LEVEL", "info")
+	fixturesMode := getEnv("FIXTURES_MODE", "")
+	mode := getMode(logLevel, fixturesMode)
+
+	// Replay mode replays recordings; recording stays available to live mode
+	switch {
+	case mode == ModeReplay:
+		fixturesMode = "replay"
+	case fixturesMode == "replay":
+		log.Printf("Ignoring FIXTURES_MODE=replay in %s mode", mode)
+		fixturesMode = ""
+	}
+
+	// Demo data is not worth keeping, so demo mode stores in memory by default
+	storeDriver := "sqlite"
+	if mode == ModeDemo {
+		storeDriver = "memory"
+	}
+
 	return &Config{
-		Port:                getEnv("PORT", "8080"),
-		ScrapeInterval:      getDurationEnv("SCRAPE_INTERVAL", 300) * time.Second,
+		Mode:                  mode,
+		DemoSeed:              int64(getIntEnv("DEMO_SEED", 0)),
+		Port:                  getEnv("PORT", "8080"),
+		ScrapeInterval:        getDurationEnv("SCRAPE_INTERVAL", 300) * time.Second,
+		SiteSchedules:         getScheduleEnv("SITE_SCHEDULES"),
+		ScrapeJitter:          getDurationEnv("SCRAPE_JITTER", 30) * time.Second,
+		AdaptiveScheduling:    getBoolEnv("ADAPTIVE_SCHEDULING", true),
 		MaxConcurrentScrapers: getIntEnv("MAX_CONCURRENT_SCRAPERS", 5),
-		RequestTimeout:      getDurationEnv("REQUEST_TIMEOUT", 30) * time.Second,
-		ChromeHeadless:      getBoolEnv("CHROME_HEADLESS", true),
-		ChromeDisableGPU:    getBoolEnv("CHROME_DISABLE_GPU", true),
-		RateLimitRequests:   getIntEnv("RATE_LIMIT_REQUESTS", 100),
-		RateLimitWindow:     getDurationEnv("RATE_LIMIT_WINDOW", 60) * time.Second,
-		LogLevel:            getEnv("LOG_LEVEL", "info"),
+		RequestTimeout:        getDurationEnv("REQUEST_TIMEOUT", 30) * time.Second,
+		ChromeHeadless:        getBoolEnv("CHROME_HEADLESS", true),
+		ChromeDisableGPU:      getBoolEnv("CHROME_DISABLE_GPU", true),
+		BrowserPoolSize:       getIntEnv("BROWSER_POOL_SIZE", 2),
+		BrowserMaxUses:        getIntEnv("BROWSER_MAX_USES", 50),
+		RateLimitRequests:     getIntEnv("RATE_LIMIT_REQUESTS", 100),
+		RateLimitWindow:       getDurationEnv("RATE_LIMIT_WINDOW", 60) * time.Second,
+		RateLimitBurst:        getIntEnv("RATE_LIMIT_BURST", 5),
+		CrawlDelay:            getDurationEnv("CRAWL_DELAY", 1) * time.Second,
+		SiteRateLimits:        getRateLimitEnv("SITE_RATE_LIMITS"),
+		RespectRobotsTxt:      getBoolEnv("RESPECT_ROBOTS_TXT", false),
+		ProxyFile:             getEnv("PROXY_FILE", ""),
+		ProxyCheckURL:         getEnv("PROXY_CHECK_URL", "https://www.google.com/generate_204"),
+		ProxyCheckInterval:    getDurationEnv("PROXY_CHECK_INTERVAL", 300) * time.Second,
+		ProxyBanThreshold:     getIntEnv("PROXY_BAN_THRESHOLD", 3),
+		ProxyBanCooldown:      getDurationEnv("PROXY_BAN_COOLDOWN", 3600) * time.Second,
+		ProxyMaxBans:          getIntEnv("PROXY_MAX_BANS", 5),
+		RotateIdentities:      getBoolEnv("ROTATE_IDENTITIES", true),
+		LogLevel:              logLevel,
+		MatchKickoffWindow:    getDurationEnv("MATCH_KICKOFF_WINDOW", 120) * time.Minute,
+		AliasFile:             getEnv("ALIAS_FILE", "data/aliases.json"),
+		AliasDecisionsFile:    getEnv("ALIAS_DECISIONS_FILE", "data/alias_decisions.json"),
+		FuzzyMatchThreshold:   getFloatEnv("FUZZY_MATCH_THRESHOLD", 0.92),
+		FuzzyReviewThreshold:  getFloatEnv("FUZZY_REVIEW_THRESHOLD", 0.75),
+		ArbitrageMaxOddsAge:   getDurationEnv("ARBITRAGE_MAX_ODDS_AGE", 900) * time.Second,
+		ArbitrageStake:        getFloatEnv("ARBITRAGE_STAKE", 1000),
+		TaxProfilesFile:       getEnv("TAX_PROFILES_FILE", "data/tax_profiles.json"),
+		StoreDriver:           getEnv("STORE_DRIVER", storeDriver),
+		DatabasePath:          getEnv("DATABASE_PATH", "data/odds.db"),
+		SitesDir:              getEnv("SITES_DIR", "sites"),
+		SiteAPIs:              getBoolEnv("SITE_APIS", true),
+		CapturePatterns:       getPatternEnv("CAPTURE_PATTERNS"),
+		CaptureDir:            getEnv("CAPTURE_DIR", ""),
+		FixturesMode:          fixturesMode,
+		FixturesDir:           getEnv("FIXTURES_DIR", "data/fixtures"),
+		SiteBaseURLs:          getMapEnv("SITE_BASE_URLS"),
+		HealthMinEvents:       getIntEnv("HEALTH_MIN_EVENTS", 3),
+		HealthMinParsed:       getFloatEnv("HEALTH_MIN_PARSED", 0.8),
+		HealthMinKnownLeagues: getFloatEnv("HEALTH_MIN_KNOWN_LEAGUES", 0.3),
+		HealthMaxDrop:         getFloatEnv("HEALTH_MAX_DROP", 0.5),
+		AlertWebhookURL:       getEnv("ALERT_WEBHOOK_URL", ""),
+		ScrapeRetries:         getIntEnv("SCRAPE_RETRIES", 2),
+		RetryBaseDelay:        getDurationEnv("RETRY_BASE_DELAY", 2) * time.Second,
+		RetryMaxDelay:         getDurationEnv("RETRY_MAX_DELAY", 30) * time.Second,
+		RetryJitter:           getFloatEnv("RETRY_JITTER", 0.2),
+		BreakerThreshold:      getIntEnv("BREAKER_THRESHOLD", 5),
+		BreakerCooldown:       getDurationEnv("BREAKER_COOLDOWN", 300) * time.Second,
+	}
+}
+
+// getMode reads MODE, falling back to the older LOG_LEVEL=demo and
+// FIXTURES_MODE=replay switches when it is unset
+func getMode(logLevel, fixturesMode string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("MODE"))); mode {
+	case ModeLive, ModeDemo, ModeReplay:
+		return mode
+	case "":
+	default:
+		log.Printf("Ignoring unknown MODE %q, expected live, demo or replay", mode)
 	}
+
+	switch {
+	case logLevel == "demo":
+		log.Printf("LOG_LEVEL=demo is deprecated, set MODE=demo instead")
+		return ModeDemo
+	case fixturesMode == "replay":
+		return ModeReplay
+	}
+	return ModeLive
 }
 
 func getEnv(key, defaultValue string) string {
@@ -57,6 +206,91 @@ func getDurationEnv(key string, defaultValue int) time.Duration {
 	return time.Duration(defaultValue)
 }
 
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getScheduleEnv parses per-site intervals in seconds, e.g. "sportpesa=120,odibets=600"
+func getScheduleEnv(key string) map[string]time.Duration {
+	schedules := make(map[string]time.Duration)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		siteID, value, found := strings.Cut(entry, "=")
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || err != nil || seconds <= 0 {
+			log.Printf("Ignoring invalid %s entry %q", key, entry)
+			continue
+		}
+		schedules[strings.TrimSpace(siteID)] = time.Duration(seconds) * time.Second
+	}
+	return schedules
+}
+
+// getRateLimitEnv parses per-site request rates as requests/seconds, e.g.
+// "betika=30/60,sportpesa=20/60"
+func getRateLimitEnv(key string) map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	for siteID, value := range getMapEnv(key) {
+		requests, seconds, found := strings.Cut(value, "/")
+		count, countErr := strconv.Atoi(strings.TrimSpace(requests))
+		window, windowErr := strconv.Atoi(strings.TrimSpace(seconds))
+		if !found || countErr != nil || windowErr != nil || count <= 0 || window <= 0 {
+			log.Printf("Ignoring invalid %s entry %q", key, siteID+"="+value)
+			continue
+		}
+		limits[siteID] = RateLimit{Requests: count, Window: time.Duration(window) * time.Second}
+	}
+	return limits
+}
+
+// getMapEnv parses comma-separated key=value pairs such as
+// "betika=http://localhost:9090/betika,*=http://localhost:9090"
+func getMapEnv(key string) map[string]string {
+	values := make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, "=")
+		if !found || strings.TrimSpace(value) == "" {
+			log.Printf("Ignoring invalid %s entry %q", key, entry)
+			continue
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// getPatternEnv parses per-site URL patterns such as
+// "betika=api\.betika\.com/v1/uo/;odibets=/v5/sportsbook". Entries are split on
+// semicolons since patterns may contain commas.
+func getPatternEnv(key string) map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		siteID, value, found := strings.Cut(entry, "=")
+		pattern, err := regexp.Compile(strings.TrimSpace(value))
+		if !found || err != nil || value == "" {
+			log.Printf("Ignoring invalid %s entry %q", key, entry)
+			continue
+		}
+		patterns[strings.TrimSpace(siteID)] = pattern
+	}
+	return patterns
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -64,4 +298,4 @@ func getBoolEnv(key string, defaultValue bool) bool {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
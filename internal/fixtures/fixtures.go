@@ -0,0 +1,230 @@
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Modes
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+// Artifact kinds
+const (
+	// KindAPI is the body of a direct call to a bookmaker's JSON API
+	KindAPI = "api"
+	// KindPage is the HTML of a page loaded in Chrome
+	KindPage = "page"
+	// KindCapture is a JSON response captured while a page loaded
+	KindCapture = "capture"
+)
+
+// manifestFile lists a recording's artifacts next to their bodies
+const manifestFile = "manifest.json"
+
+// timestampLayout names recording directories so they sort by time
+const timestampLayout = "20060102T150405"
+
+// ErrExhausted is returned when a replay has no recorded artifact left for a
+// fetch, which usually means the scraper's requests changed since recording
+var ErrExhausted = errors.New("no recorded artifact left")
+
+// Artifact is one raw page or response a scraper fetched
+type Artifact struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+	File string `json:"file"`
+	Body []byte `json:"-"`
+}
+
+// Recording is everything one scrape of a site fetched, in fetch order
+type Recording struct {
+	SiteID     string     `json:"site_id"`
+	RecordedAt time.Time  `json:"recorded_at"`
+	Artifacts  []Artifact `json:"artifacts"`
+}
+
+// Session records the artifacts of one scrape, or replays them in place of
+// the network. Scrapers find it on their context.
+type Session struct {
+	replay    bool
+	recording Recording
+	cursor    map[string]int
+	mutex     sync.Mutex
+}
+
+type sessionKey struct{}
+
+// NewRecorder starts recording a scrape of siteID
+func NewRecorder(siteID string) *Session {
+	return &Session{recording: Recording{SiteID: siteID, RecordedAt: time.Now()}}
+}
+
+// NewReplay replays a recording
+func NewReplay(recording Recording) *Session {
+	return &Session{replay: true, recording: recording, cursor: make(map[string]int)}
+}
+
+// WithSession attaches a session to a scrape's context
+func WithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// FromContext returns the context's session, or nil outside record and
+// replay modes
+func FromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// Replaying reports whether a scrape should read recorded artifacts instead
+// of the network. It is false for a nil session.
+func (s *Session) Replaying() bool {
+	return s != nil && s.replay
+}
+
+// Now is the time a scrape runs at: the recording time during replay, so
+// replayed results are reproducible, and the current time otherwise
+func Now(ctx context.Context) time.Time {
+	if session := FromContext(ctx); session.Replaying() {
+		return session.recording.RecordedAt
+	}
+	return time.Now()
+}
+
+// Record adds a fetched artifact to a recording session
+func (s *Session) Record(kind, url string, body []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.recording.Artifacts = append(s.recording.Artifacts, Artifact{Kind: kind, URL: url, Body: body})
+}
+
+// Next returns the next recorded artifact of a kind
+func (s *Session) Next(kind string) (Artifact, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	seen := 0
+	for _, artifact := range s.recording.Artifacts {
+		if artifact.Kind != kind {
+			continue
+		}
+		if seen == s.cursor[kind] {
+			s.cursor[kind]++
+			return artifact, nil
+		}
+		seen++
+	}
+	return Artifact{}, fmt.Errorf("%w: %s for %s", ErrExhausted, kind, s.recording.SiteID)
+}
+
+// Drain returns every recorded artifact of a kind not yet replayed
+func (s *Session) Drain(kind string) []Artifact {
+	var artifacts []Artifact
+	for {
+		artifact, err := s.Next(kind)
+		if err != nil {
+			return artifacts
+		}
+		artifacts = append(artifacts, artifact)
+	}
+}
+
+// Fetch runs fetch and records what it returned, or during replay returns the
+// next recorded artifact of kind without fetching
+func Fetch(ctx context.Context, kind, url string, fetch func() ([]byte, error)) ([]byte, error) {
+	session := FromContext(ctx)
+	if session.Replaying() {
+		artifact, err := session.Next(kind)
+		return artifact.Body, err
+	}
+
+	body, err := fetch()
+	if err == nil && session != nil {
+		session.Record(kind, url, body)
+	}
+	return body, err
+}
+
+// Recording returns what a session has recorded so far
+func (s *Session) Recording() Recording {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	recording := s.recording
+	recording.Artifacts = append([]Artifact(nil), s.recording.Artifacts...)
+	return recording
+}
+
+// Save writes a recording to <dir>/<site>/<timestamp>/, with a manifest and
+// one file per artifact, and returns its path
+func Save(dir string, recording Recording) (string, error) {
+	path := filepath.Join(dir, recording.SiteID, recording.RecordedAt.UTC().Format(timestampLayout))
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	for i := range recording.Artifacts {
+		artifact := &recording.Artifacts[i]
+		ext := ".json"
+		if artifact.Kind == KindPage {
+			ext = ".html"
+		}
+		artifact.File = fmt.Sprintf("%02d_%s%s", i+1, artifact.Kind, ext)
+		if err := os.WriteFile(filepath.Join(path, artifact.File), artifact.Body, 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", artifact.File, err)
+		}
+	}
+
+	manifest, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(path, manifestFile), manifest, 0644); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return path, nil
+}
+
+// Load reads a recording saved by Save
+func Load(path string) (Recording, error) {
+	data, err := os.ReadFile(filepath.Join(path, manifestFile))
+	if err != nil {
+		return Recording{}, err
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return Recording{}, fmt.Errorf("invalid manifest in %s: %w", path, err)
+	}
+	for i := range recording.Artifacts {
+		artifact := &recording.Artifacts[i]
+		if artifact.Body, err = os.ReadFile(filepath.Join(path, artifact.File)); err != nil {
+			return Recording{}, err
+		}
+	}
+	return recording, nil
+}
+
+// Latest loads a site's most recent recording in dir
+func Latest(dir, siteID string) (Recording, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, siteID, "*", manifestFile))
+	if err != nil {
+		return Recording{}, err
+	}
+	if len(paths) == 0 {
+		return Recording{}, fmt.Errorf("no recordings for %s in %s", siteID, dir)
+	}
+	sort.Strings(paths)
+	return Load(filepath.Dir(paths[len(paths)-1]))
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"strconv"
+)
+
+// Market types
+const (
+	MarketMatchResult   = "1x2"
+	MarketOverUnder     = "over_under"
+	MarketBTTS          = "btts"
+	MarketDoubleChance  = "double_chance"
+	MarketDrawNoBet     = "draw_no_bet"
+	MarketAsianHandicap = "asian_handicap"
+	MarketCorrectScore  = "correct_score"
+)
+
+// ValidMarket reports whether market is one of the supported market types
+func ValidMarket(market string) bool {
+	switch market {
+	case MarketMatchResult, MarketOverUnder, MarketBTTS, MarketDoubleChance,
+		MarketDrawNoBet, MarketAsianHandicap, MarketCorrectScore:
+		return true
+	}
+	return false
+}
+
+// Market periods
+const (
+	PeriodFullTime  = "ft"
+	PeriodFirstHalf = "1h"
+)
+
+// Market selections. Correct score selections are the score itself, e.g. "2-1".
+const (
+	SelectionHome       = "home"
+	SelectionDraw       = "draw"
+	SelectionAway       = "away"
+	SelectionOver       = "over"
+	SelectionUnder      = "under"
+	SelectionYes        = "yes"
+	SelectionNo         = "no"
+	SelectionHomeOrDraw = "1x"
+	SelectionHomeOrAway = "12"
+	SelectionDrawOrAway = "x2"
+)
+
+// Price is a single selection's decimal price within a market. Line holds the
+// market parameter, such as "2.5" for over/under or "-0.5" for a handicap.
+// NetValue is only filled when a comparison is made on after-tax prices.
+type Price struct {
+	Market    string  `json:"market"`
+	Line      string  `json:"line,omitempty"`
+	Period    string  `json:"period"`
+	Selection string  `json:"selection"`
+	Value     float64 `json:"value"`
+	NetValue  float64 `json:"net_value,omitempty"`
+}
+
+// Key identifies the selection a price is for, independent of its value
+func (p Price) Key() string {
+	return p.Market + "|" + p.Line + "|" + p.Period + "|" + p.Selection
+}
+
+// FormatLine renders a numeric market line the way it is stored on a Price
+func FormatLine(line float64) string {
+	return strconv.FormatFloat(line, 'f', -1, 64)
+}
+
+// legacyField ties one of the fixed Odds fields to its generic market
+type legacyField struct {
+	price Price
+	field func(o *Odds) *float64
+}
+
+var legacyFields = []legacyField{
+	{Price{Market: MarketMatchResult, Period: PeriodFullTime, Selection: SelectionHome}, func(o *Odds) *float64 { return &o.HomeWin }},
+	{Price{Market: MarketMatchResult, Period: PeriodFullTime, Selection: SelectionDraw}, func(o *Odds) *float64 { return &o.Draw }},
+	{Price{Market: MarketMatchResult, Period: PeriodFullTime, Selection: SelectionAway}, func(o *Odds) *float64 { return &o.AwayWin }},
+	{Price{Market: MarketOverUnder, Line: "2.5", Period: PeriodFullTime, Selection: SelectionOver}, func(o *Odds) *float64 { return &o.Over25 }},
+	{Price{Market: MarketOverUnder, Line: "2.5", Period: PeriodFullTime, Selection: SelectionUnder}, func(o *Odds) *float64 { return &o.Under25 }},
+	{Price{Market: MarketBTTS, Period: PeriodFullTime, Selection: SelectionYes}, func(o *Odds) *float64 { return &o.BTTS }},
+	{Price{Market: MarketBTTS, Period: PeriodFullTime, Selection: SelectionNo}, func(o *Odds) *float64 { return &o.BTTSNo }},
+}
+
+// Price looks up the price for a selection
+func (o *Odds) Price(market, line, period, selection string) (float64, bool) {
+	for _, p := range o.Markets {
+		if p.Market == market && p.Line == line && p.Period == period && p.Selection == selection {
+			return p.Value, true
+		}
+	}
+	return 0, false
+}
+
+// SetPrice adds or replaces the price for a selection
+func (o *Odds) SetPrice(market, line, period, selection string, value float64) {
+	for i, p := range o.Markets {
+		if p.Market == market && p.Line == line && p.Period == period && p.Selection == selection {
+			o.Markets[i].Value = value
+			return
+		}
+	}
+	o.Markets = append(o.Markets, Price{
+		Market:    market,
+		Line:      line,
+		Period:    period,
+		Selection: selection,
+		Value:     value,
+	})
+}
+
+// SetMatchResult sets the full-time home/draw/away prices. A zero draw price
+// is skipped for sports without a draw.
+func (o *Odds) SetMatchResult(home, draw, away float64) {
+	o.SetPrice(MarketMatchResult, "", PeriodFullTime, SelectionHome, home)
+	if draw > 0 {
+		o.SetPrice(MarketMatchResult, "", PeriodFullTime, SelectionDraw, draw)
+	}
+	o.SetPrice(MarketMatchResult, "", PeriodFullTime, SelectionAway, away)
+}
+
+// SetGoalMarkets sets the full-time over/under 2.5 and both-teams-to-score
+// prices. Zero prices are skipped so sites that do not offer a market leave it
+// absent rather than priced at zero.
+func (o *Odds) SetGoalMarkets(over25, under25, bttsYes, bttsNo float64) {
+	if over25 > 0 {
+		o.SetPrice(MarketOverUnder, "2.5", PeriodFullTime, SelectionOver, over25)
+	}
+	if under25 > 0 {
+		o.SetPrice(MarketOverUnder, "2.5", PeriodFullTime, SelectionUnder, under25)
+	}
+	if bttsYes > 0 {
+		o.SetPrice(MarketBTTS, "", PeriodFullTime, SelectionYes, bttsYes)
+	}
+	if bttsNo > 0 {
+		o.SetPrice(MarketBTTS, "", PeriodFullTime, SelectionNo, bttsNo)
+	}
+}
+
+// SyncMarkets keeps the generic markets and the fixed legacy fields in step,
+// so scrapers may fill either and API clients still see both.
+func (o *Odds) SyncMarkets() {
+	for _, legacy := range legacyFields {
+		field := legacy.field(o)
+		p := legacy.price
+		if value, ok := o.Price(p.Market, p.Line, p.Period, p.Selection); ok {
+			*field = value
+		} else if *field > 0 {
+			o.SetPrice(p.Market, p.Line, p.Period, p.Selection, *field)
+		}
+	}
+}
@@ -6,66 +6,299 @@ import (
 
 // BettingSite represents a betting platform
 type BettingSite struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	URL      string `json:"url"`
-	Active   bool   `json:"active"`
-	LastScrape time.Time `json:"last_scrape"`
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	URL        string     `json:"url"`
+	Active     bool       `json:"active"`
+	LastScrape time.Time  `json:"last_scrape"`
+	Tax        TaxProfile `json:"tax"`
 }
 
-// Match represents a sports match
+// TaxProfile describes how a site applies betting taxes to a bet. Excise duty
+// is charged on the amount paid in, so a site that does not absorb it places
+// only amount/(1+ExciseDuty) as the stake. Withholding tax is charged on
+// winnings (payout less stake), or on the whole payout when WHTOnGross is set.
+type TaxProfile struct {
+	ExciseDuty     float64 `json:"excise_duty"`
+	AbsorbsExcise  bool    `json:"absorbs_excise"`
+	WithholdingTax float64 `json:"withholding_tax"`
+	WHTOnGross     bool    `json:"wht_on_gross"`
+}
+
+// KenyaTaxProfile is the statutory 12.5% excise duty on stakes and 20%
+// withholding tax on winnings, passed on to the bettor
+var KenyaTaxProfile = TaxProfile{
+	ExciseDuty:     0.125,
+	WithholdingTax: 0.20,
+}
+
+// Match represents a sports match. Once resolved by the scraper manager, ID is
+// the canonical fixture key and SourceIDs maps each site to its own match ID.
 type Match struct {
-	ID          string    `json:"id"`
-	HomeTeam    string    `json:"home_team"`
-	AwayTeam    string    `json:"away_team"`
-	Sport       string    `json:"sport"`
-	League      string    `json:"league"`
-	MatchTime   time.Time `json:"match_time"`
-	Status      string    `json:"status"`
+	ID         string            `json:"id"`
+	HomeTeam   string            `json:"home_team"`
+	AwayTeam   string            `json:"away_team"`
+	Sport      string            `json:"sport"`
+	League     string            `json:"league"`
+	MatchTime  time.Time         `json:"match_time"`
+	Status     string            `json:"status"`
+	SourceIDs  map[string]string `json:"source_ids,omitempty"`
+	Provenance string            `json:"provenance"`
 }
 
-// Odds represents betting odds for a match
+// Odds represents betting odds for a match. Markets carries every price the
+// site offers; the fixed fields mirror the common markets for older clients.
 type Odds struct {
-	ID         string    `json:"id"`
-	MatchID    string    `json:"match_id"`
-	SiteID     string    `json:"site_id"`
-	SiteName   string    `json:"site_name"`
-	HomeWin    float64   `json:"home_win"`
-	Draw       float64   `json:"draw,omitempty"`
-	AwayWin    float64   `json:"away_win"`
-	Over25     float64   `json:"over_2_5,omitempty"`
-	Under25    float64   `json:"under_2_5,omitempty"`
-	BTTS       float64   `json:"btts,omitempty"`
-	ScrapedAt  time.Time `json:"scraped_at"`
+	ID            string    `json:"id"`
+	MatchID       string    `json:"match_id"`
+	SiteID        string    `json:"site_id"`
+	SiteName      string    `json:"site_name"`
+	HomeWin       float64   `json:"home_win"`
+	Draw          float64   `json:"draw,omitempty"`
+	AwayWin       float64   `json:"away_win"`
+	Over25        float64   `json:"over_2_5,omitempty"`
+	Under25       float64   `json:"under_2_5,omitempty"`
+	BTTS          float64   `json:"btts,omitempty"`
+	BTTSNo        float64   `json:"btts_no,omitempty"`
+	Markets       []Price   `json:"markets,omitempty"`
+	SourceMatchID string    `json:"source_match_id,omitempty"`
+	Provenance    string    `json:"provenance"`
+	ScrapedAt     time.Time `json:"scraped_at"`
 }
 
 // BestOdds represents the best odds found across all sites
 type BestOdds struct {
-	Match       Match              `json:"match"`
-	BestHomeWin *OddsComparison    `json:"best_home_win"`
-	BestDraw    *OddsComparison    `json:"best_draw,omitempty"`
-	BestAwayWin *OddsComparison    `json:"best_away_win"`
-	BestOver25  *OddsComparison    `json:"best_over_2_5,omitempty"`
-	BestUnder25 *OddsComparison    `json:"best_under_2_5,omitempty"`
-	BestBTTS    *OddsComparison    `json:"best_btts,omitempty"`
-	AllOdds     []Odds             `json:"all_odds"`
-	UpdatedAt   time.Time          `json:"updated_at"`
+	Match       Match           `json:"match"`
+	BestHomeWin *OddsComparison `json:"best_home_win"`
+	BestDraw    *OddsComparison `json:"best_draw,omitempty"`
+	BestAwayWin *OddsComparison `json:"best_away_win"`
+	BestOver25  *OddsComparison `json:"best_over_2_5,omitempty"`
+	BestUnder25 *OddsComparison `json:"best_under_2_5,omitempty"`
+	BestBTTS    *OddsComparison `json:"best_btts,omitempty"`
+	BestBTTSNo  *OddsComparison `json:"best_btts_no,omitempty"`
+	BestPrices  []BestPrice     `json:"best_prices,omitempty"`
+	AllOdds     []Odds          `json:"all_odds"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// BestPrice is the best price for one market selection and the site offering it
+type BestPrice struct {
+	Price
+	SiteID     string `json:"site_id"`
+	SiteName   string `json:"site_name"`
+	Provenance string `json:"provenance"`
 }
 
 // OddsComparison represents the best odds for a specific market
 type OddsComparison struct {
-	Value    float64 `json:"value"`
-	SiteID   string  `json:"site_id"`
-	SiteName string  `json:"site_name"`
+	Value      float64 `json:"value"`
+	NetValue   float64 `json:"net_value,omitempty"`
+	SiteID     string  `json:"site_id"`
+	SiteName   string  `json:"site_name"`
+	Provenance string  `json:"provenance"`
+}
+
+// Arbitrage represents a set of prices across sites covering every outcome of a
+// market for less than the total stake
+type Arbitrage struct {
+	ID                 string         `json:"id"`
+	Match              Match          `json:"match"`
+	Market             string         `json:"market"`
+	Line               string         `json:"line,omitempty"`
+	Period             string         `json:"period"`
+	ImpliedProbability float64        `json:"implied_probability"`
+	ProfitPercent      float64        `json:"profit_percent"`
+	TotalStake         float64        `json:"total_stake"`
+	Legs               []ArbitrageLeg `json:"legs"`
+	DetectedAt         time.Time      `json:"detected_at"`
+}
+
+// ArbitrageLeg is one outcome of an arbitrage and the stake to place on it
+type ArbitrageLeg struct {
+	Selection  string    `json:"selection"`
+	Value      float64   `json:"value"`
+	NetValue   float64   `json:"net_value,omitempty"`
+	SiteID     string    `json:"site_id"`
+	SiteName   string    `json:"site_name"`
+	Provenance string    `json:"provenance"`
+	Stake      float64   `json:"stake"`
+	Return     float64   `json:"return"`
+	ScrapedAt  time.Time `json:"scraped_at"`
+}
+
+// MarketMargin is one site's overround on a market and its de-vigged fair prices
+type MarketMargin struct {
+	Market        string      `json:"market"`
+	Line          string      `json:"line,omitempty"`
+	Period        string      `json:"period"`
+	Overround     float64     `json:"overround"`
+	MarginPercent float64     `json:"margin_percent"`
+	Method        string      `json:"method"`
+	Selections    []FairPrice `json:"selections"`
+}
+
+// FairPrice is a selection's quoted price alongside its no-vig fair value
+type FairPrice struct {
+	Selection       string  `json:"selection"`
+	Value           float64 `json:"value"`
+	FairProbability float64 `json:"fair_probability"`
+	FairOdds        float64 `json:"fair_odds"`
+}
+
+// SiteMargins holds every market margin one site offers on a match
+type SiteMargins struct {
+	MatchID  string         `json:"match_id"`
+	SiteID   string         `json:"site_id"`
+	SiteName string         `json:"site_name"`
+	Markets  []MarketMargin `json:"markets"`
+}
+
+// LeagueMargin is a site's average margin across a league's matches
+type LeagueMargin struct {
+	Sport         string  `json:"sport"`
+	League        string  `json:"league"`
+	SiteID        string  `json:"site_id"`
+	SiteName      string  `json:"site_name"`
+	MarginPercent float64 `json:"average_margin_percent"`
+	Markets       int     `json:"markets"`
+	Cheapest      bool    `json:"cheapest"`
+}
+
+// PricePoint is a selection's price at one site from the moment it was
+// first seen or last changed
+type PricePoint struct {
+	MatchID    string    `json:"match_id"`
+	SiteID     string    `json:"site_id"`
+	Market     string    `json:"market"`
+	Line       string    `json:"line,omitempty"`
+	Period     string    `json:"period"`
+	Selection  string    `json:"selection"`
+	Value      float64   `json:"value"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// PriceTick is one step in a price movement
+type PriceTick struct {
+	Value      float64   `json:"value"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// PriceMovement is the price history of one selection at one site
+type PriceMovement struct {
+	Market       string      `json:"market"`
+	Line         string      `json:"line,omitempty"`
+	Period       string      `json:"period"`
+	Selection    string      `json:"selection"`
+	SiteID       string      `json:"site_id"`
+	SiteName     string      `json:"site_name"`
+	Opening      float64     `json:"opening"`
+	Current      float64     `json:"current"`
+	DriftPercent float64     `json:"drift_percent"`
+	Changes      int         `json:"changes"`
+	OpenedAt     time.Time   `json:"opened_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	History      []PriceTick `json:"history"`
+}
+
+// MatchHistory is the odds movement of every selection on a fixture
+type MatchHistory struct {
+	Match     Match           `json:"match"`
+	Movements []PriceMovement `json:"movements"`
+}
+
+// Provenances, telling where a match or price came from. Live prices were
+// read from a bookmaker's pages and API prices from its JSON API; demo prices
+// are simulated, replay prices come from recorded fixtures and manual prices
+// were entered by hand.
+const (
+	ProvenanceLive   = "live"
+	ProvenanceAPI    = "api"
+	ProvenanceDemo   = "demo"
+	ProvenanceReplay = "replay"
+	ProvenanceManual = "manual"
+)
+
+// Provenances lists every provenance
+var Provenances = []string{
+	ProvenanceLive, ProvenanceAPI, ProvenanceDemo, ProvenanceReplay, ProvenanceManual,
+}
+
+// Scrape statuses. A degraded scrape returned data that failed validation,
+// which usually means the site's layout changed under the scraper.
+const (
+	ScrapeOK       = "ok"
+	ScrapeDegraded = "degraded"
+	ScrapeFailed   = "failed"
+)
+
+// Scrape error kinds, telling why a failed scrape failed
+const (
+	ErrorTimeout       = "timeout"
+	ErrorBlocked       = "blocked"
+	ErrorCaptcha       = "captcha"
+	ErrorRateLimited   = "rate_limited"
+	ErrorUnavailable   = "unavailable"
+	ErrorLayoutChanged = "layout_changed"
+	ErrorEmptyPage     = "empty_page"
+	ErrorParse         = "parse_error"
+	ErrorCircuitOpen   = "circuit_open"
+	ErrorUnknown       = "unknown"
+)
+
+// ErrorKinds lists every scrape error kind
+var ErrorKinds = []string{
+	ErrorTimeout, ErrorBlocked, ErrorCaptcha, ErrorRateLimited, ErrorUnavailable,
+	ErrorLayoutChanged, ErrorEmptyPage, ErrorParse, ErrorCircuitOpen, ErrorUnknown,
 }
 
 // ScrapeResult represents the result of a scraping operation
 type ScrapeResult struct {
-	SiteID    string    `json:"site_id"`
-	Success   bool      `json:"success"`
-	MatchCount int      `json:"match_count"`
-	OddsCount int       `json:"odds_count"`
-	Error     string    `json:"error,omitempty"`
-	Duration  time.Duration `json:"duration"`
-	ScrapedAt time.Time `json:"scraped_at"`
-}
\ No newline at end of file
+	SiteID     string        `json:"site_id"`
+	Success    bool          `json:"success"`
+	Status     string        `json:"status"`
+	Attempts   int           `json:"attempts"`
+	MatchCount int           `json:"match_count"`
+	OddsCount  int           `json:"odds_count"`
+	Error      string        `json:"error,omitempty"`
+	ErrorKind  string        `json:"error_kind,omitempty"`
+	Issues     []string      `json:"issues,omitempty"`
+	Checks     *ScrapeChecks `json:"checks,omitempty"`
+	Health     float64       `json:"health"`
+	Duration   time.Duration `json:"duration"`
+	ScrapedAt  time.Time     `json:"scraped_at"`
+}
+
+// State is the result's status, worked out from Success for results stored
+// before statuses were recorded
+func (r ScrapeResult) State() string {
+	switch {
+	case r.Status != "":
+		return r.Status
+	case r.Success:
+		return ScrapeOK
+	}
+	return ScrapeFailed
+}
+
+// ScrapeChecks are the measurements a scrape was validated on
+type ScrapeChecks struct {
+	Events             int     `json:"events"`
+	MinEvents          int     `json:"min_events"`
+	BaselineEvents     float64 `json:"baseline_events,omitempty"`
+	ParsedFraction     float64 `json:"parsed_fraction"`
+	KnownLeagues       float64 `json:"known_leagues"`
+	InvalidPrices      int     `json:"invalid_prices"`
+	ImplausibleMarkets int     `json:"implausible_markets"`
+	// Partial is set for targeted scrapes, which skip the event count checks
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Alert is raised when a site's scrapes stop being healthy, and again when
+// they recover
+type Alert struct {
+	SiteID   string    `json:"site_id"`
+	Status   string    `json:"status"`
+	Message  string    `json:"message"`
+	Issues   []string  `json:"issues,omitempty"`
+	RaisedAt time.Time `json:"raised_at"`
+}
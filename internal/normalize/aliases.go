@@ -0,0 +1,61 @@
+package normalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AliasFile is the curated dictionary of canonical team and league names,
+// each mapped to the spellings bookmakers are known to use for it.
+type AliasFile struct {
+	Teams   map[string][]string `json:"teams"`
+	Leagues map[string][]string `json:"leagues"`
+}
+
+// LoadAliasFile reads an alias dictionary from a JSON file
+func LoadAliasFile(path string) (*AliasFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias file: %w", err)
+	}
+
+	var aliases AliasFile
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse alias file %s: %w", path, err)
+	}
+	return &aliases, nil
+}
+
+// leagueCountries maps each canonical league to the folded countries of its
+// "Country - League" spellings
+func leagueCountries(leagues map[string][]string) map[string]map[string]bool {
+	countries := make(map[string]map[string]bool)
+	for canonical, spellings := range leagues {
+		for _, spelling := range spellings {
+			idx := strings.LastIndex(spelling, " - ")
+			if idx < 0 {
+				continue
+			}
+			if countries[canonical] == nil {
+				countries[canonical] = make(map[string]bool)
+			}
+			countries[canonical][Fold(spelling[:idx])] = true
+		}
+	}
+	return countries
+}
+
+// index maps every folded spelling, including the canonical name itself, to
+// the canonical name
+func index(entries map[string][]string) map[string]string {
+	idx := make(map[string]string)
+	for canonical, spellings := range entries {
+		idx[Fold(canonical)] = canonical
+		for _, spelling := range spellings {
+			idx[Fold(spelling)] = canonical
+		}
+	}
+	return idx
+}
@@ -0,0 +1,155 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are club-name affixes that bookmakers add or drop inconsistently
+var stopWords = map[string]bool{
+	"fc":  true,
+	"cf":  true,
+	"afc": true,
+	"sc":  true,
+	"the": true,
+}
+
+// Fold reduces a name to lowercase words without punctuation or club affixes,
+// so "Man. United FC" and "man united" fold to the same string.
+func Fold(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(foldAccents(name)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	kept := words[:0]
+	for _, word := range words {
+		if !stopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(kept, " ")
+}
+
+// Similarity scores how alike two folded names are, from 0 (unrelated) to 1.
+// Names are compared word by word so that "manchester city" and "manchester
+// united" share only half their words rather than most of their letters.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if a == "" || b == "" {
+		return 0
+	}
+	return tokenScore(strings.Fields(a), strings.Fields(b))
+}
+
+// tokenScore matches each word of the shorter name against the longer one,
+// treating abbreviations ("man" for "manchester") as near matches.
+func tokenScore(a, b []string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, token := range a {
+		best := 0.0
+		for _, other := range b {
+			var score float64
+			switch {
+			case token == other:
+				score = 1
+			case len(token) >= 3 && strings.HasPrefix(other, token),
+				len(other) >= 3 && strings.HasPrefix(token, other):
+				score = 0.9
+			default:
+				score = jaroWinkler(token, other)
+				if score < 0.9 {
+					score = 0
+				}
+			}
+			if score > best {
+				best = score
+			}
+		}
+		total += best
+	}
+
+	// Penalise names where the longer one carries many unmatched words
+	coverage := float64(len(a)) / float64(len(b))
+	return total / float64(len(a)) * (0.8 + 0.2*coverage)
+}
+
+func jaroWinkler(a, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 || len(br) == 0 {
+		return 0
+	}
+
+	matchRange := max(len(ar), len(br))/2 - 1
+	if matchRange < 0 {
+		matchRange = 0
+	}
+
+	aMatched := make([]bool, len(ar))
+	bMatched := make([]bool, len(br))
+	matches := 0
+	for i := range ar {
+		start := max(0, i-matchRange)
+		end := min(len(br), i+matchRange+1)
+		for j := start; j < end; j++ {
+			if bMatched[j] || ar[i] != br[j] {
+				continue
+			}
+			aMatched[i], bMatched[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ar {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if ar[i] != br[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ar)) + m/float64(len(br)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ar), len(br)) && ar[prefix] == br[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c", "ß", "ss",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ö", "O", "Ú", "U", "Ü", "U",
+)
+
+func foldAccents(s string) string {
+	return accents.Replace(s)
+}
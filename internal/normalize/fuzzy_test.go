@@ -0,0 +1,68 @@
+package normalize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Man. United FC", "man united"},
+		{"  The  Arsenal ", "arsenal"},
+		{"Atlético Madrid", "atletico madrid"},
+		{"Borussia Mönchengladbach", "borussia monchengladbach"},
+		{"1. FC Köln", "1 koln"},
+		// A name made only of affixes keeps them
+		{"FC", "fc"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.name); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"arsenal", "arsenal", 1},
+		{"arsenal", "", 0},
+		// Abbreviations score 0.9 per word
+		{"man united", "manchester united", 0.95},
+		// Half the words match; "city" and "united" share too little
+		{"manchester city", "manchester united", 0.5},
+		// Extra words in the longer name cost up to a fifth
+		{"arsenal", "arsenal london", 0.9},
+		// A one-letter typo: six matching letters and a four letter prefix
+		{"arsenal", "arsenel", 0.942857},
+		{"chelsea", "liverpool", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Similarity(%q, %q) = %.6f, want %.6f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	// The textbook examples
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961111},
+		{"dwayne", "duane", 0.84},
+		{"dixon", "dicksonx", 0.813333},
+		{"abc", "xyz", 0},
+	}
+	for _, tt := range tests {
+		if got := jaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("jaroWinkler(%q, %q) = %.6f, want %.6f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
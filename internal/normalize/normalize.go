@@ -0,0 +1,205 @@
+package normalize
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+	"betting-odds-scraper/internal/models"
+)
+
+// Kind identifies which dictionary a name belongs to
+type Kind string
+
+const (
+	KindTeam   Kind = "team"
+	KindLeague Kind = "league"
+)
+
+// Normalizer maps the team and league names each bookmaker uses onto one
+// canonical spelling, using the curated alias file first, then operator
+// decisions, then fuzzy matching against names it has already seen.
+type Normalizer struct {
+	aliases         map[Kind]map[string]string
+	known           map[Kind]map[string]string
+	countries       map[string]map[string]bool
+	decisions       map[string]Decision
+	pending         map[string]*Candidate
+	threshold       float64
+	reviewThreshold float64
+	decisionsPath   string
+	mutex           sync.RWMutex
+}
+
+// New creates a normalizer from the configured alias and decisions files. The
+// returned normalizer is always usable; an error means one of the files could
+// not be loaded and the normalizer is running without it.
+func New(cfg *config.Config) (*Normalizer, error) {
+	n := &Normalizer{
+		aliases: map[Kind]map[string]string{
+			KindTeam:   {},
+			KindLeague: {},
+		},
+		known: map[Kind]map[string]string{
+			KindTeam:   {},
+			KindLeague: {},
+		},
+		decisions:       make(map[string]Decision),
+		pending:         make(map[string]*Candidate),
+		threshold:       cfg.FuzzyMatchThreshold,
+		reviewThreshold: cfg.FuzzyReviewThreshold,
+		decisionsPath:   cfg.AliasDecisionsFile,
+	}
+
+	if cfg.AliasFile != "" {
+		aliases, err := LoadAliasFile(cfg.AliasFile)
+		if err != nil {
+			return n, err
+		}
+		n.aliases[KindTeam] = index(aliases.Teams)
+		n.aliases[KindLeague] = index(aliases.Leagues)
+		n.countries = leagueCountries(aliases.Leagues)
+	}
+
+	if err := n.loadDecisions(); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// Match returns the match with its team and league names normalized
+func (n *Normalizer) Match(match models.Match) models.Match {
+	match.HomeTeam = n.Team(match.HomeTeam)
+	match.AwayTeam = n.Team(match.AwayTeam)
+	match.League = n.League(match.League)
+	return match
+}
+
+// Team returns the canonical name for a team
+func (n *Normalizer) Team(name string) string {
+	return n.resolve(KindTeam, name, "")
+}
+
+// League returns the canonical name for a league. Names of the form
+// "England - Premier League" fall back to the part after the country when
+// the alias file spells that league with the same country. Any other country
+// goes to the review queue, since "Scotland - Premier League" is not the EPL.
+func (n *Normalizer) League(name string) string {
+	if idx := strings.LastIndex(name, " - "); idx >= 0 {
+		n.mutex.RLock()
+		_, full := n.aliases[KindLeague][Fold(name)]
+		canonical, short := n.aliases[KindLeague][Fold(name[idx+3:])]
+		sameCountry := n.countries[canonical][Fold(name[:idx])]
+		n.mutex.RUnlock()
+		switch {
+		case full || !short:
+		case sameCountry:
+			return canonical
+		default:
+			return n.resolve(KindLeague, name, canonical)
+		}
+	}
+	return n.resolve(KindLeague, name, "")
+}
+
+// Curated reports whether a name is a canonical name or spelling in the
+// alias file, or one an operator confirmed
+func (n *Normalizer) Curated(kind Kind, name string) bool {
+	folded := Fold(name)
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	if _, ok := n.aliases[kind][folded]; ok {
+		return true
+	}
+	for _, decision := range n.decisions {
+		if decision.Kind == kind && decision.Confirmed && Fold(decision.Canonical) == folded {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the canonical name for name. A suggested name replaces
+// fuzzy matching: an undecided name is queued for review paired with it.
+func (n *Normalizer) resolve(kind Kind, name, suggested string) string {
+	raw := strings.TrimSpace(name)
+	folded := Fold(raw)
+	if folded == "" {
+		return raw
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if canonical, ok := n.aliases[kind][folded]; ok {
+		return canonical
+	}
+
+	if decision, ok := n.decisions[decisionKey(kind, folded)]; ok {
+		if decision.Confirmed {
+			return decision.Canonical
+		}
+		n.known[kind][folded] = raw
+		return raw
+	}
+
+	if canonical, ok := n.known[kind][folded]; ok {
+		return canonical
+	}
+
+	if candidate, ok := n.pending[candidateID(kind, folded)]; ok {
+		candidate.Seen++
+		candidate.LastSeen = time.Now()
+		return candidate.Raw
+	}
+
+	if suggested != "" {
+		n.enqueue(kind, raw, suggested, Similarity(folded, Fold(suggested)))
+		return raw
+	}
+
+	best, score := n.bestMatch(kind, folded)
+	switch {
+	case score >= n.threshold:
+		return best
+	case score >= n.reviewThreshold:
+		n.enqueue(kind, raw, best, score)
+		return raw
+	}
+
+	n.known[kind][folded] = raw
+	return raw
+}
+
+// bestMatch finds the closest canonical name to folded; the caller must hold
+// the lock
+func (n *Normalizer) bestMatch(kind Kind, folded string) (string, float64) {
+	best, bestScore := "", 0.0
+	for _, names := range []map[string]string{n.aliases[kind], n.known[kind]} {
+		for spelling, canonical := range names {
+			if score := Similarity(folded, spelling); score > bestScore {
+				best, bestScore = canonical, score
+			}
+		}
+	}
+	return best, bestScore
+}
+
+// enqueue adds a pairing to the review queue; the caller must hold the lock
+func (n *Normalizer) enqueue(kind Kind, raw, suggested string, score float64) {
+	id := candidateID(kind, Fold(raw))
+	now := time.Now()
+
+	n.pending[id] = &Candidate{
+		ID:        id,
+		Kind:      kind,
+		Raw:       raw,
+		Suggested: suggested,
+		Score:     score,
+		Seen:      1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+}
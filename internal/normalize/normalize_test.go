@@ -0,0 +1,93 @@
+package normalize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"betting-odds-scraper/internal/config"
+)
+
+func testNormalizer(t *testing.T) *Normalizer {
+	t.Helper()
+	n, err := New(&config.Config{
+		AliasFile:            filepath.Join("..", "..", "data", "aliases.json"),
+		FuzzyMatchThreshold:  0.92,
+		FuzzyReviewThreshold: 0.75,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestLeague(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		review bool
+	}{
+		{"Premier League", "Premier League", false},
+		{"England - Premier League", "Premier League", false},
+		{"Kenya - Premier League", "Kenyan Premier League", false},
+		// The country prefix is dropped when it is the league's own
+		{"England - EPL", "Premier League", false},
+		{"Spain - Primera Division", "La Liga", false},
+		{"Germany - 1. Bundesliga", "Bundesliga", false},
+		// Another country's league of the same name is not merged
+		{"Scotland - Premier League", "Scotland - Premier League", true},
+		{"Wales - Championship", "Wales - Championship", true},
+		{"Brazil - Serie A", "Brazil - Serie A", true},
+		{"Austria - Bundesliga", "Austria - Bundesliga", true},
+		// A league spelled with no country cannot confirm one
+		{"Asia - UEL", "Asia - UEL", true},
+	}
+
+	n := testNormalizer(t)
+	for _, tt := range tests {
+		if got := n.League(tt.name); got != tt.want {
+			t.Errorf("League(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	queued := make(map[string]Candidate)
+	for _, candidate := range n.ReviewQueue() {
+		queued[candidate.Raw] = candidate
+	}
+	for _, tt := range tests {
+		if _, ok := queued[tt.name]; ok != tt.review {
+			t.Errorf("%q queued for review: %v, want %v", tt.name, ok, tt.review)
+		}
+	}
+	if got := queued["Scotland - Premier League"].Suggested; got != "Premier League" {
+		t.Errorf("Scotland - Premier League suggested %q, want Premier League", got)
+	}
+}
+
+func TestLeagueDecision(t *testing.T) {
+	n := testNormalizer(t)
+	n.League("Scotland - Premier League")
+	n.League("Austria - Bundesliga")
+
+	for _, candidate := range n.ReviewQueue() {
+		var err error
+		switch candidate.Raw {
+		case "Scotland - Premier League":
+			_, err = n.Confirm(candidate.ID, "Scottish Premiership")
+		case "Austria - Bundesliga":
+			_, err = n.Reject(candidate.ID)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := n.League("Scotland - Premier League"); got != "Scottish Premiership" {
+		t.Errorf("confirmed league resolved to %q", got)
+	}
+	if got := n.League("Austria - Bundesliga"); got != "Austria - Bundesliga" {
+		t.Errorf("rejected league resolved to %q", got)
+	}
+	if queue := n.ReviewQueue(); len(queue) != 0 {
+		t.Errorf("decided leagues still queued: %+v", queue)
+	}
+}
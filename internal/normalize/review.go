@@ -0,0 +1,174 @@
+package normalize
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ErrCandidateNotFound is returned when deciding on an unknown review candidate
+var ErrCandidateNotFound = errors.New("review candidate not found")
+
+// Candidate is a low-confidence pairing waiting for an operator decision
+type Candidate struct {
+	ID        string    `json:"id"`
+	Kind      Kind      `json:"kind"`
+	Raw       string    `json:"raw"`
+	Suggested string    `json:"suggested"`
+	Score     float64   `json:"score"`
+	Seen      int       `json:"seen"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Decision is an operator's verdict on a pairing. A confirmed decision maps
+// Raw to Canonical; a rejected one keeps Raw as its own name.
+type Decision struct {
+	Kind      Kind      `json:"kind"`
+	Raw       string    `json:"raw"`
+	Canonical string    `json:"canonical,omitempty"`
+	Confirmed bool      `json:"confirmed"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+func candidateID(kind Kind, folded string) string {
+	sum := sha1.Sum([]byte(string(kind) + "|" + folded))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func decisionKey(kind Kind, folded string) string {
+	return string(kind) + "|" + folded
+}
+
+// ReviewQueue returns the pending pairings, most frequently seen first
+func (n *Normalizer) ReviewQueue() []Candidate {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	queue := make([]Candidate, 0, len(n.pending))
+	for _, candidate := range n.pending {
+		queue = append(queue, *candidate)
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		if queue[i].Seen != queue[j].Seen {
+			return queue[i].Seen > queue[j].Seen
+		}
+		return queue[i].ID < queue[j].ID
+	})
+	return queue
+}
+
+// Confirm accepts a pending pairing. If canonical is empty the suggested name
+// is used, otherwise the operator's own canonical name is recorded.
+func (n *Normalizer) Confirm(id, canonical string) (Decision, error) {
+	return n.decide(id, canonical, true)
+}
+
+// Reject marks a pending pairing as wrong so it is never suggested again
+func (n *Normalizer) Reject(id string) (Decision, error) {
+	return n.decide(id, "", false)
+}
+
+func (n *Normalizer) decide(id, canonical string, confirmed bool) (Decision, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	candidate, exists := n.pending[id]
+	if !exists {
+		return Decision{}, fmt.Errorf("%w: %s", ErrCandidateNotFound, id)
+	}
+
+	decision := Decision{
+		Kind:      candidate.Kind,
+		Raw:       candidate.Raw,
+		Confirmed: confirmed,
+		DecidedAt: time.Now(),
+	}
+	if confirmed {
+		decision.Canonical = candidate.Suggested
+		if canonical != "" {
+			decision.Canonical = canonical
+		}
+	}
+
+	n.decisions[decisionKey(candidate.Kind, Fold(candidate.Raw))] = decision
+	delete(n.pending, id)
+
+	if err := n.saveDecisions(); err != nil {
+		return decision, err
+	}
+	return decision, nil
+}
+
+// Decisions returns every recorded operator decision
+func (n *Normalizer) Decisions() []Decision {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	decisions := make([]Decision, 0, len(n.decisions))
+	for _, decision := range n.decisions {
+		decisions = append(decisions, decision)
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].DecidedAt.Before(decisions[j].DecidedAt)
+	})
+	return decisions
+}
+
+func (n *Normalizer) loadDecisions() error {
+	if n.decisionsPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(n.decisionsPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read decisions file: %w", err)
+	}
+
+	var decisions []Decision
+	if err := json.Unmarshal(data, &decisions); err != nil {
+		return fmt.Errorf("failed to parse decisions file %s: %w", n.decisionsPath, err)
+	}
+	for _, decision := range decisions {
+		n.decisions[decisionKey(decision.Kind, Fold(decision.Raw))] = decision
+	}
+	return nil
+}
+
+// saveDecisions writes all decisions to disk; the caller must hold the lock
+func (n *Normalizer) saveDecisions() error {
+	if n.decisionsPath == "" {
+		return nil
+	}
+
+	decisions := make([]Decision, 0, len(n.decisions))
+	for _, decision := range n.decisions {
+		decisions = append(decisions, decision)
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].DecidedAt.Before(decisions[j].DecidedAt)
+	})
+
+	data, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(n.decisionsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create decisions directory: %w", err)
+	}
+	tmp := n.decisionsPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write decisions file: %w", err)
+	}
+	return os.Rename(tmp, n.decisionsPath)
+}
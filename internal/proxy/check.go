@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds one proxy health check
+const checkTimeout = 10 * time.Second
+
+// maxCheckFailures is how many checks in a row a proxy that has worked may
+// fail before it is taken out of rotation; one that fails its first check is
+// taken out at once, and one passing check puts either back
+const maxCheckFailures = 2
+
+// Start checks every proxy now and then every check interval, until Close
+func (p *Pool) Start() {
+	if len(p.proxies) == 0 || p.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.CheckAll(context.Background())
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the health checks
+func (p *Pool) Close() {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+}
+
+// CheckAll checks every proxy that has not been evicted, concurrently
+func (p *Pool) CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, proxy := range p.proxies {
+		p.mutex.Lock()
+		evicted := proxy.evicted
+		p.mutex.Unlock()
+		if evicted {
+			continue
+		}
+
+		wg.Add(1)
+		go func(proxy *Proxy) {
+			defer wg.Done()
+			p.record(proxy, p.check(ctx, proxy))
+		}(proxy)
+	}
+	wg.Wait()
+}
+
+// check fetches the check URL through a proxy. Any answer from the target
+// counts as healthy; a proxy error or a 5xx from the proxy does not.
+func (p *Pool) check(ctx context.Context, proxy *Proxy) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.checkURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: proxy.transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusProxyAuthRequired || resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// record applies a health check result to a proxy
+func (p *Pool) record(proxy *Proxy, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	first := proxy.checkedAt.IsZero()
+	proxy.checkedAt = time.Now()
+	if err == nil {
+		if !proxy.healthy {
+			log.Printf("Proxy %s is healthy again", proxy)
+		}
+		proxy.healthy = true
+		proxy.failures = 0
+		proxy.checkError = ""
+		return
+	}
+
+	proxy.failures++
+	proxy.checkError = err.Error()
+	if proxy.healthy && (first || proxy.failures >= maxCheckFailures) {
+		proxy.healthy = false
+		log.Printf("Proxy %s failed %d health check(s) in a row, taking it out of rotation: %v", proxy, proxy.failures, err)
+	}
+}
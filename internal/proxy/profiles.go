@@ -0,0 +1,67 @@
+package proxy
+
+// Profile is a browser identity: the user agent and matching platform a
+// scraper presents, and the window size its browser tab reports
+type Profile struct {
+	UserAgent      string `json:"user_agent"`
+	Platform       string `json:"platform"`
+	AcceptLanguage string `json:"accept_language"`
+	Width          int64  `json:"width"`
+	Height         int64  `json:"height"`
+}
+
+// profiles are common desktop Chrome and Edge setups. Only Chromium browsers
+// are listed, since the pages are rendered by Chrome and a Firefox or Safari
+// user agent would not match what the browser does. Mobile profiles are left
+// out because bookmakers serve a different layout to phones.
+var profiles = []Profile{
+	{
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Platform:       "Win32",
+		AcceptLanguage: "en-US,en;q=0.9",
+		Width:          1920,
+		Height:         1080,
+	},
+	{
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
+		Platform:       "Win32",
+		AcceptLanguage: "en-GB,en;q=0.9,sw;q=0.8",
+		Width:          1366,
+		Height:         768,
+	},
+	{
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+		Platform:       "Win32",
+		AcceptLanguage: "en-US,en;q=0.9,sw;q=0.8",
+		Width:          1536,
+		Height:         864,
+	},
+	{
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Platform:       "MacIntel",
+		AcceptLanguage: "en-US,en;q=0.9",
+		Width:          1440,
+		Height:         900,
+	},
+	{
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
+		Platform:       "MacIntel",
+		AcceptLanguage: "en-GB,en;q=0.9",
+		Width:          1280,
+		Height:         800,
+	},
+	{
+		UserAgent:      "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Platform:       "Linux x86_64",
+		AcceptLanguage: "en-US,en;q=0.9",
+		Width:          1600,
+		Height:         900,
+	},
+	{
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36",
+		Platform:       "Win32",
+		AcceptLanguage: "en-KE,en;q=0.9,sw;q=0.8",
+		Width:          1280,
+		Height:         720,
+	},
+}
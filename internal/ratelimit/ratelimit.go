@@ -0,0 +1,306 @@
+// Package ratelimit paces the requests scrapers make to each bookmaker, so
+// that browser navigations, page XHR calls and API requests to one domain
+// share a single budget however many scrapers run at once.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrDisallowed is returned for a URL that the site's robots.txt disallows
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Policy is how hard one domain may be hit. Requests per Window are spread
+// evenly once a burst of Burst requests is spent, and consecutive requests
+// are at least CrawlDelay apart.
+type Policy struct {
+	Requests   int           `yaml:"requests"`
+	Window     time.Duration `yaml:"window"`
+	Burst      int           `yaml:"burst"`
+	CrawlDelay time.Duration `yaml:"crawl_delay"`
+}
+
+// withDefaults fills the fields a policy leaves unset from defaults
+func (p Policy) withDefaults(defaults Policy) Policy {
+	if p.Requests <= 0 || p.Window <= 0 {
+		p.Requests, p.Window = defaults.Requests, defaults.Window
+	}
+	if p.Burst <= 0 {
+		p.Burst = defaults.Burst
+	}
+	if p.CrawlDelay <= 0 {
+		p.CrawlDelay = defaults.CrawlDelay
+	}
+	if p.Burst < 1 {
+		p.Burst = 1
+	}
+	return p
+}
+
+// interval is the time it takes to earn one request, or 0 when the rate is
+// unlimited
+func (p Policy) interval() time.Duration {
+	if p.Requests <= 0 || p.Window <= 0 {
+		return 0
+	}
+	return p.Window / time.Duration(p.Requests)
+}
+
+// stricter combines two policies for sites sharing a domain, keeping the
+// slower rate, the smaller burst and the longer delay
+func stricter(a, b Policy) Policy {
+	if b.interval() > a.interval() {
+		a.Requests, a.Window = b.Requests, b.Window
+	}
+	if b.Burst < a.Burst {
+		a.Burst = b.Burst
+	}
+	if b.CrawlDelay > a.CrawlDelay {
+		a.CrawlDelay = b.CrawlDelay
+	}
+	return a
+}
+
+// DomainStats describes one domain's limiter for metrics
+type DomainStats struct {
+	Domain     string
+	Policy     Policy
+	Requests   int
+	Waited     time.Duration
+	Disallowed int
+}
+
+// Limiter holds a token bucket per configured bookmaker domain. Requests to
+// other domains, such as CDNs and analytics, are not limited.
+type Limiter struct {
+	defaults Policy
+	robots   *robotsCache
+
+	mutex   sync.Mutex
+	domains map[string]*bucket
+}
+
+// bucket paces the requests to one domain; it is guarded by the limiter lock
+type bucket struct {
+	policy      Policy
+	robotsDelay time.Duration
+	tokens      float64
+	refilled    time.Time
+	next        time.Time
+
+	requests   int
+	waited     time.Duration
+	disallowed int
+}
+
+// New creates a limiter with the configured default policy, obeying
+// robots.txt when configured to
+func New(cfg *config.Config) *Limiter {
+	l := &Limiter{
+		defaults: Policy{
+			Requests:   cfg.RateLimitRequests,
+			Window:     cfg.RateLimitWindow,
+			Burst:      cfg.RateLimitBurst,
+			CrawlDelay: cfg.CrawlDelay,
+		},
+		domains: make(map[string]*bucket),
+	}
+	if cfg.RespectRobotsTxt {
+		l.robots = newRobotsCache()
+	}
+	return l
+}
+
+// Configure limits the domain of rawURL to policy, filling unset fields from
+// the defaults. A domain shared by several sites keeps the strictest policy.
+func (l *Limiter) Configure(rawURL string, policy Policy) {
+	domain := DomainOf(rawURL)
+	if domain == "" {
+		return
+	}
+	policy = policy.withDefaults(l.defaults)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if b, exists := l.domains[domain]; exists {
+		b.policy = stricter(b.policy, policy)
+		return
+	}
+	l.domains[domain] = &bucket{policy: policy, tokens: float64(policy.Burst)}
+}
+
+// Wait blocks until a request to rawURL may be sent, or ctx ends. It returns
+// ErrDisallowed without waiting when robots.txt disallows the URL. A nil
+// limiter never waits.
+func (l *Limiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return nil
+	}
+	domain := DomainOf(rawURL)
+	target, err := url.Parse(rawURL)
+	if err != nil || !l.limits(domain) {
+		return nil
+	}
+
+	if l.robots != nil {
+		rules := l.robots.rules(ctx, target)
+		l.mutex.Lock()
+		b := l.domains[domain]
+		b.robotsDelay = rules.crawlDelay
+		allowed := rules.allowed(target.RequestURI())
+		if !allowed {
+			b.disallowed++
+		}
+		l.mutex.Unlock()
+		if !allowed {
+			return fmt.Errorf("%w: %s", ErrDisallowed, rawURL)
+		}
+	}
+
+	l.mutex.Lock()
+	delay := l.domains[domain].reserve(time.Now())
+	l.mutex.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Backoff holds every request to rawURL's domain back for d, as asked by a
+// 429 response's Retry-After header
+func (l *Limiter) Backoff(rawURL string, d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	domain := DomainOf(rawURL)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	b, exists := l.domains[domain]
+	if !exists {
+		return
+	}
+	if until := time.Now().Add(d); until.After(b.next) {
+		b.next = until
+		log.Printf("Backing off %s for %s", domain, d)
+	}
+}
+
+// Stats returns every limited domain's policy and counters, by domain
+func (l *Limiter) Stats() []DomainStats {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	stats := make([]DomainStats, 0, len(l.domains))
+	for domain, b := range l.domains {
+		policy := b.policy
+		if b.robotsDelay > policy.CrawlDelay {
+			policy.CrawlDelay = b.robotsDelay
+		}
+		stats = append(stats, DomainStats{
+			Domain:     domain,
+			Policy:     policy,
+			Requests:   b.requests,
+			Waited:     b.waited,
+			Disallowed: b.disallowed,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Domain < stats[j].Domain
+	})
+	return stats
+}
+
+func (l *Limiter) limits(domain string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, exists := l.domains[domain]
+	return exists
+}
+
+// reserve takes the next request slot and returns how long the caller must
+// wait for it. Tokens may go negative, queueing callers behind each other.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	at := now
+	if interval := b.policy.interval(); interval > 0 {
+		earned := float64(now.Sub(b.refilled)) / float64(interval)
+		b.tokens = math.Min(float64(b.policy.Burst), b.tokens+earned)
+		b.refilled = now
+		b.tokens--
+		if b.tokens < 0 {
+			at = now.Add(time.Duration(-b.tokens * float64(interval)))
+		}
+	}
+	if at.Before(b.next) {
+		at = b.next
+	}
+
+	delay := b.policy.CrawlDelay
+	if b.robotsDelay > delay {
+		delay = b.robotsDelay
+	}
+	b.next = at.Add(delay)
+	b.requests++
+	b.waited += at.Sub(now)
+	return at.Sub(now)
+}
+
+// DomainOf returns the registrable domain of rawURL, such as betika.com for
+// api.betika.com, so a site's API and pages share a budget. Hosts without a
+// public suffix, such as localhost, are returned as they are.
+func DomainOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
+
+type contextKey struct{}
+
+// NewContext attaches a limiter to a scrape's context
+func NewContext(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the context's limiter, or nil when requests are not
+// limited
+func FromContext(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(contextKey{}).(*Limiter)
+	return l
+}
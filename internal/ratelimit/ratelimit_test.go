@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+)
+
+func TestBucketPacing(t *testing.T) {
+	// 60 requests a minute is one a second, after a burst of 3
+	b := &bucket{policy: Policy{Requests: 60, Window: time.Minute, Burst: 3}, tokens: 3}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		delays = append(delays, b.reserve(now))
+	}
+	want := []time.Duration{0, 0, 0, time.Second, 2 * time.Second}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("delays %v, want %v", delays, want)
+		}
+	}
+
+	// The queue has drained and earned one token three seconds later
+	if delay := b.reserve(now.Add(3 * time.Second)); delay != 0 {
+		t.Errorf("delay after draining %v, want 0", delay)
+	}
+}
+
+func TestBucketCrawlDelay(t *testing.T) {
+	b := &bucket{policy: Policy{Burst: 10, CrawlDelay: 2 * time.Second}, tokens: 10}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	if delay := b.reserve(now); delay != 0 {
+		t.Fatalf("first request waited %v", delay)
+	}
+	if delay := b.reserve(now.Add(500 * time.Millisecond)); delay != 1500*time.Millisecond {
+		t.Errorf("second request waited %v, want 1.5s", delay)
+	}
+
+	// robots.txt can only lengthen the delay
+	b.robotsDelay = 5 * time.Second
+	if delay := b.reserve(now.Add(2 * time.Second)); delay != 2*time.Second {
+		t.Errorf("third request waited %v, want 2s", delay)
+	}
+	if delay := b.reserve(now.Add(4 * time.Second)); delay != 5*time.Second {
+		t.Errorf("fourth request waited %v, want 5s", delay)
+	}
+}
+
+func TestSharedDomain(t *testing.T) {
+	l := New(&config.Config{RateLimitRequests: 100, RateLimitWindow: time.Minute, RateLimitBurst: 5})
+	l.Configure("https://www.betika.com", Policy{})
+	l.Configure("https://www.betika.com/en-ke", Policy{Requests: 10, Window: time.Minute, CrawlDelay: time.Second})
+
+	stats := l.Stats()
+	if len(stats) != 1 || stats[0].Domain != "betika.com" {
+		t.Fatalf("got %+v, want one betika.com domain", stats)
+	}
+	want := Policy{Requests: 10, Window: time.Minute, Burst: 5, CrawlDelay: time.Second}
+	if stats[0].Policy != want {
+		t.Errorf("policy %+v, want the stricter %+v", stats[0].Policy, want)
+	}
+
+	// API calls count against the site's domain; other hosts are not limited
+	for _, url := range []string{"https://api.betika.com/v1/uo/matches", "https://cdn.example.com/app.js"} {
+		if err := l.Wait(context.Background(), url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := l.Stats(); stats[0].Requests != 1 {
+		t.Errorf("betika.com counted %d requests, want 1", stats[0].Requests)
+	}
+}
+
+func TestDomainOf(t *testing.T) {
+	tests := map[string]string{
+		"https://www.ke.sportpesa.com/api/upcoming": "sportpesa.com",
+		"https://www.betway.co.ke/sport/soccer":     "betway.co.ke",
+		"http://localhost:9090/betika":              "localhost",
+		"http://127.0.0.1:9090/odibets":             "127.0.0.1",
+	}
+	for url, want := range tests {
+		if got := DomainOf(url); got != want {
+			t.Errorf("DomainOf(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	rules := parseRobots([]byte(`
+# Everyone else
+User-agent: Googlebot
+Disallow: /
+
+User-agent: *
+Disallow: /api/
+Allow: /api/v1/uo/
+Disallow: /*.pdf$
+Crawl-delay: 2.5
+`))
+
+	if rules.crawlDelay != 2500*time.Millisecond {
+		t.Errorf("crawl delay %v, want 2.5s", rules.crawlDelay)
+	}
+	for path, want := range map[string]bool{
+		"/":                      true,
+		"/api/account":           false,
+		"/api/v1/uo/matches?p=1": true,
+		"/terms.pdf":             false,
+		"/terms.pdf?download=1":  true,
+	} {
+		if got := rules.allowed(path); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	// A group naming us replaces the * group
+	named := parseRobots([]byte("User-agent: *\nDisallow: /\n\nUser-agent: betting-odds-scraper\nDisallow: /private\n"))
+	if !named.allowed("/odds") || named.allowed("/private/x") {
+		t.Error("the betting-odds-scraper group should apply instead of *")
+	}
+}
+
+func TestRobotsDisallow(t *testing.T) {
+	var robotsFetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsFetches.Add(1)
+			w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	l := New(&config.Config{RespectRobotsTxt: true})
+	l.Configure(server.URL, Policy{})
+
+	if err := l.Wait(context.Background(), server.URL+"/odds"); err != nil {
+		t.Fatalf("allowed URL: %v", err)
+	}
+	if err := l.Wait(context.Background(), server.URL+"/admin/users"); !errors.Is(err, ErrDisallowed) {
+		t.Fatalf("got %v, want ErrDisallowed", err)
+	}
+	if robotsFetches.Load() != 1 {
+		t.Errorf("robots.txt fetched %d times, want once", robotsFetches.Load())
+	}
+	if stats := l.Stats(); stats[0].Requests != 1 || stats[0].Disallowed != 1 {
+		t.Errorf("got %+v, want one request and one disallowed", stats[0])
+	}
+}
+
+func TestRobotsCallerCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+	}))
+	defer server.Close()
+
+	c := newRobotsCache()
+	target, _ := url.Parse(server.URL + "/admin")
+
+	// The first caller gives up while robots.txt is still loading
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if rules := c.rules(ctx, target); !rules.allowed(target.Path) {
+		t.Error("a caller that gave up was handed the rules")
+	}
+
+	// The fetch carries on for the next caller rather than caching a failure
+	close(release)
+	if rules := c.rules(context.Background(), target); rules.allowed(target.Path) {
+		t.Error("/admin allowed after robots.txt loaded")
+	}
+}
+
+func TestWaitHonoursContext(t *testing.T) {
+	l := New(&config.Config{RateLimitRequests: 1, RateLimitWindow: time.Hour, RateLimitBurst: 1})
+	l.Configure("https://www.odibets.com", Policy{})
+
+	if err := l.Wait(context.Background(), "https://www.odibets.com/"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "https://www.odibets.com/sports"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context's deadline", err)
+	}
+}
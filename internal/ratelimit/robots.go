@@ -0,0 +1,225 @@
+package ratelimit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsAgent is the product token matched against robots.txt groups, and
+// sent when fetching robots.txt
+const robotsAgent = "betting-odds-scraper"
+
+// robotsTTL is how long a host's robots.txt is trusted before refetching it
+const robotsTTL = 24 * time.Hour
+
+// robotsRetry is how soon a robots.txt that could not be fetched is tried
+// again; the host is treated as allowing everything meanwhile
+const robotsRetry = 10 * time.Minute
+
+// maxRobotsSize bounds how much of a robots.txt is read, as RFC 9309 allows
+const maxRobotsSize = 500 << 10
+
+// robotsRules are the rules of the robots.txt group that applies to us
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// allowed reports whether path may be fetched. The longest matching rule
+// wins, and Allow wins a tie.
+func (r robotsRules) allowed(path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed, longest = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
+
+// parseRobots reads the group of a robots.txt that names robotsAgent, or the
+// "*" group when none does
+func parseRobots(body []byte) robotsRules {
+	var named, wildcard robotsRules
+	var matchesNamed, matchesAny, foundNamed bool
+	inAgents := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// Consecutive user-agent lines share the rules that follow them
+			if !inAgents {
+				matchesNamed, matchesAny = false, false
+				inAgents = true
+			}
+			agent := strings.ToLower(value)
+			if agent == "*" {
+				matchesAny = true
+			} else if agent == robotsAgent {
+				matchesNamed, foundNamed = true, true
+			}
+			continue
+		}
+		inAgents = false
+
+		for _, group := range []struct {
+			rules   *robotsRules
+			matches bool
+		}{{&named, matchesNamed}, {&wildcard, matchesAny}} {
+			if group.matches {
+				group.rules.add(key, value)
+			}
+		}
+	}
+
+	if foundNamed {
+		return named
+	}
+	return wildcard
+}
+
+// add applies one line of a group
+func (r *robotsRules) add(key, value string) {
+	switch key {
+	case "allow", "disallow":
+		// An empty Disallow allows everything
+		if value == "" {
+			return
+		}
+		r.rules = append(r.rules, robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)})
+	case "crawl-delay":
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+			r.crawlDelay = time.Duration(seconds * float64(time.Second))
+		}
+	}
+}
+
+// robotsPattern compiles a path rule, where * matches anything and a final $
+// anchors the end of the path
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+	if anchored {
+		pattern += "$"
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// robotsCache fetches each host's robots.txt once per robotsTTL
+type robotsCache struct {
+	client *http.Client
+
+	mutex sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	ready   chan struct{}
+	rules   robotsRules
+	expires time.Time
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{
+		client: &http.Client{Timeout: 10 * time.Second},
+		hosts:  make(map[string]*robotsEntry),
+	}
+}
+
+// rules returns the rules for target's host, fetching its robots.txt when
+// not cached. Concurrent callers share one fetch, which runs detached from
+// ctx so a caller giving up does not leave the host cached as allowing all.
+func (c *robotsCache) rules(ctx context.Context, target *url.URL) robotsRules {
+	origin := target.Scheme + "://" + target.Host
+
+	c.mutex.Lock()
+	entry, exists := c.hosts[origin]
+	if exists {
+		select {
+		case <-entry.ready:
+			exists = time.Now().Before(entry.expires)
+		default:
+		}
+	}
+	if !exists {
+		entry = &robotsEntry{ready: make(chan struct{})}
+		c.hosts[origin] = entry
+		go c.load(context.WithoutCancel(ctx), entry, origin, target.Host)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.rules
+	case <-ctx.Done():
+		return robotsRules{}
+	}
+}
+
+// load fetches a host's robots.txt into entry. A failed fetch allows
+// everything until it is retried after robotsRetry.
+func (c *robotsCache) load(ctx context.Context, entry *robotsEntry, origin, host string) {
+	rules, err := c.fetch(ctx, origin+"/robots.txt")
+	entry.rules, entry.expires = rules, time.Now().Add(robotsTTL)
+	if err != nil {
+		log.Printf("Failed to fetch robots.txt of %s, allowing all: %v", host, err)
+		entry.expires = time.Now().Add(robotsRetry)
+	}
+	close(entry.ready)
+}
+
+// fetch reads a robots.txt. A missing file allows everything.
+func (c *robotsCache) fetch(ctx context.Context, robotsURL string) (robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return robotsRules{}, err
+	}
+	req.Header.Set("User-Agent", robotsAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return robotsRules{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return robotsRules{}, nil
+	case resp.StatusCode != http.StatusOK:
+		return robotsRules{}, fmt.Errorf("%s returned %s", robotsURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return robotsRules{}, err
+	}
+	return parseRobots(body), nil
+}
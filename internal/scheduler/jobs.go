@@ -0,0 +1,256 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// jobHistory is the number of finished jobs kept for status lookups
+const jobHistory = 100
+
+// Job states
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+)
+
+// Site states within a job
+const (
+	SitePending      = "pending"
+	SiteRunning      = "running"
+	SiteSucceeded    = "succeeded"
+	SiteFailed       = "failed"
+	SiteDegraded     = "degraded"
+	SiteDeduplicated = "deduplicated"
+)
+
+// Job triggers
+const (
+	TriggerManual    = "manual"
+	TriggerScheduled = "scheduled"
+)
+
+// Job is an asynchronous scrape of one or more sites
+type Job struct {
+	ID         string                   `json:"id"`
+	Trigger    string                   `json:"trigger"`
+	Status     string                   `json:"status"`
+	Sites      []string                 `json:"sites"`
+	Progress   map[string]*SiteProgress `json:"progress"`
+	Completed  int                      `json:"completed"`
+	Succeeded  int                      `json:"succeeded"`
+	CreatedAt  time.Time                `json:"created_at"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+
+	done chan struct{}
+}
+
+// refreshHolder marks a site in flight for an adaptive fixture refresh
+// rather than a job
+const refreshHolder = "refresh"
+
+// ErrRefreshing is returned when every requested site is in the middle of an
+// adaptive fixture refresh
+var ErrRefreshing = errors.New("sites are being refreshed, try again shortly")
+
+// SiteProgress is the state of one site within a job. A site already being
+// scraped by another job is not scraped twice; DuplicateOf names that job, or
+// is "refresh" for an adaptive fixture refresh.
+type SiteProgress struct {
+	Status      string               `json:"status"`
+	DuplicateOf string               `json:"duplicate_of,omitempty"`
+	Result      *models.ScrapeResult `json:"result,omitempty"`
+}
+
+// Trigger starts an asynchronous scrape of the given sites, or of every site
+// when none are given. Sites already being scraped are left to the job that
+// is scraping them; when that covers every site no job is started, and
+// running holds the jobs scraping them instead.
+func (s *Scheduler) Trigger(siteIDs []string) (job Job, running []Job, err error) {
+	started, busy, err := s.startJob(siteIDs, TriggerManual)
+	if err != nil {
+		return Job{}, nil, err
+	}
+	if started == nil {
+		for _, other := range busy {
+			running = append(running, s.snapshotJob(other))
+		}
+		return Job{}, running, nil
+	}
+	return s.snapshotJob(started), nil, nil
+}
+
+// Job returns a scrape job by ID
+func (s *Scheduler) Job(id string) (Job, bool) {
+	s.jobsMutex.Lock()
+	job, exists := s.jobs[id]
+	s.jobsMutex.Unlock()
+	if !exists {
+		return Job{}, false
+	}
+	return s.snapshotJob(job), true
+}
+
+// Jobs returns the most recent scrape jobs, newest first
+func (s *Scheduler) Jobs() []Job {
+	s.jobsMutex.Lock()
+	order := make([]string, len(s.jobOrder))
+	copy(order, s.jobOrder)
+	s.jobsMutex.Unlock()
+
+	jobs := make([]Job, 0, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		if job, exists := s.Job(order[i]); exists {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+// startJob starts a job for the given sites. When every site is already being
+// scraped it starts none and returns the jobs scraping them, or ErrRefreshing
+// when only adaptive refreshes are.
+func (s *Scheduler) startJob(siteIDs []string, trigger string) (*Job, []*Job, error) {
+	if len(siteIDs) == 0 {
+		siteIDs = s.manager.SiteIDs()
+	}
+	// A site listed twice is scraped and counted once
+	unique := make([]string, 0, len(siteIDs))
+	for _, siteID := range siteIDs {
+		if !s.knownSite(siteID) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnknownSite, siteID)
+		}
+		if !slices.Contains(unique, siteID) {
+			unique = append(unique, siteID)
+		}
+	}
+	siteIDs = unique
+
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	pending := make([]string, 0, len(siteIDs))
+	for _, siteID := range siteIDs {
+		if _, busy := s.inFlight[siteID]; !busy {
+			pending = append(pending, siteID)
+		}
+	}
+	if len(pending) == 0 {
+		var running []*Job
+		for _, siteID := range siteIDs {
+			if job, exists := s.jobs[s.inFlight[siteID]]; exists && !slices.Contains(running, job) {
+				running = append(running, job)
+			}
+		}
+		if len(running) == 0 {
+			return nil, nil, ErrRefreshing
+		}
+		return nil, running, nil
+	}
+
+	job := &Job{
+		ID:        newJobID(),
+		Trigger:   trigger,
+		Status:    JobRunning,
+		Sites:     siteIDs,
+		Progress:  make(map[string]*SiteProgress, len(siteIDs)),
+		CreatedAt: time.Now(),
+		done:      make(chan struct{}),
+	}
+	for _, siteID := range siteIDs {
+		if other, busy := s.inFlight[siteID]; busy {
+			job.Progress[siteID] = &SiteProgress{Status: SiteDeduplicated, DuplicateOf: other}
+			job.Completed++
+			continue
+		}
+		job.Progress[siteID] = &SiteProgress{Status: SitePending}
+		s.inFlight[siteID] = job.ID
+	}
+
+	s.jobs[job.ID] = job
+	s.jobOrder = append(s.jobOrder, job.ID)
+	// Forget the oldest finished jobs; running jobs are kept until they finish
+	for len(s.jobOrder) > jobHistory && s.jobs[s.jobOrder[0]].Status == JobCompleted {
+		delete(s.jobs, s.jobOrder[0])
+		s.jobOrder = s.jobOrder[1:]
+	}
+
+	go s.runJob(job, pending)
+	return job, nil, nil
+}
+
+func (s *Scheduler) runJob(job *Job, siteIDs []string) {
+	log.Printf("Starting %s scrape job %s for %d sites", job.Trigger, job.ID, len(siteIDs))
+	ctx, cancel := context.WithTimeout(context.Background(), s.manager.ScrapeTimeout(len(siteIDs)))
+	defer cancel()
+
+	s.manager.ScrapeSites(ctx, siteIDs, func(siteID string, result *models.ScrapeResult) {
+		s.jobsMutex.Lock()
+		defer s.jobsMutex.Unlock()
+
+		progress := job.Progress[siteID]
+		switch {
+		case result == nil:
+			progress.Status = SiteRunning
+		case result.Success:
+			progress.Status = SiteSucceeded
+			progress.Result = result
+			job.Completed++
+			job.Succeeded++
+		case result.State() == models.ScrapeDegraded:
+			progress.Status = SiteDegraded
+			progress.Result = result
+			job.Completed++
+		default:
+			progress.Status = SiteFailed
+			progress.Result = result
+			job.Completed++
+		}
+	})
+
+	s.jobsMutex.Lock()
+	for _, siteID := range siteIDs {
+		if s.inFlight[siteID] == job.ID {
+			delete(s.inFlight, siteID)
+		}
+	}
+	job.Status = JobCompleted
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	succeeded := job.Succeeded
+	s.jobsMutex.Unlock()
+	close(job.done)
+
+	log.Printf("Scrape job %s completed: %d/%d sites successful", job.ID, succeeded, len(siteIDs))
+}
+
+// snapshotJob copies a job so it can be read without holding the lock
+func (s *Scheduler) snapshotJob(job *Job) Job {
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	snapshot := *job
+	snapshot.Sites = append([]string(nil), job.Sites...)
+	snapshot.Progress = make(map[string]*SiteProgress, len(job.Progress))
+	for siteID, progress := range job.Progress {
+		copied := *progress
+		snapshot.Progress[siteID] = &copied
+	}
+	return snapshot
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("job_%d", time.Now().UnixNano())
+	}
+	return "job_" + hex.EncodeToString(b)
+}
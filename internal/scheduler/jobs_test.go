@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+)
+
+func TestTriggerDedup(t *testing.T) {
+	manager := testManager(t)
+	release := make(chan struct{})
+	kickoff := time.Now().Add(time.Hour)
+	for _, siteID := range []string{"booka", "bookb", "bookc"} {
+		registerSite(t, manager, siteID, kickoff, release)
+	}
+	s := New(manager, config.New())
+
+	// A site listed twice is scraped once
+	first, running, err := s.Trigger([]string{"booka", "booka"})
+	if err != nil || running != nil {
+		t.Fatalf("Trigger(booka) = %v, %v", running, err)
+	}
+	if len(first.Sites) != 1 || first.Progress["booka"].Status != SitePending {
+		t.Errorf("first job %+v, want booka pending", first)
+	}
+	second, _, err := s.Trigger([]string{"bookb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A busy site is left to the job scraping it
+	third, running, err := s.Trigger([]string{"booka", "bookc"})
+	if err != nil || running != nil {
+		t.Fatalf("Trigger(booka, bookc) = %v, %v", running, err)
+	}
+	if progress := third.Progress["booka"]; progress.Status != SiteDeduplicated || progress.DuplicateOf != first.ID || third.Completed != 1 {
+		t.Errorf("busy booka in %+v, want deduplicated to %s", progress, first.ID)
+	}
+
+	// When every site is busy no job starts, and each job scraping them is
+	// returned once
+	tests := []struct {
+		sites []string
+		want  []string
+	}{
+		{[]string{"booka"}, []string{first.ID}},
+		{[]string{"booka", "bookb"}, []string{first.ID, second.ID}},
+		{[]string{"bookb", "booka", "bookb"}, []string{second.ID, first.ID}},
+	}
+	for _, tt := range tests {
+		job, running, err := s.Trigger(tt.sites)
+		if err != nil || job.ID != "" {
+			t.Errorf("Trigger(%v) started %+v, %v", tt.sites, job, err)
+			continue
+		}
+		var ids []string
+		for _, other := range running {
+			ids = append(ids, other.ID)
+		}
+		if len(ids) != len(tt.want) || (len(ids) > 0 && ids[0] != tt.want[0]) || (len(ids) > 1 && ids[1] != tt.want[1]) {
+			t.Errorf("Trigger(%v) returned jobs %v, want %v", tt.sites, ids, tt.want)
+		}
+	}
+
+	close(release)
+	for _, id := range []string{first.ID, second.ID, third.ID} {
+		s.jobsMutex.Lock()
+		done := s.jobs[id].done
+		s.jobsMutex.Unlock()
+		<-done
+	}
+	if job, _ := s.Job(first.ID); job.Status != JobCompleted || job.Completed != 1 {
+		t.Errorf("first job %+v, want its site completed", job)
+	}
+
+	// A site busy only with an adaptive refresh has no job to point to
+	if !s.claimRefresh("booka") {
+		t.Fatal("booka should be free to refresh")
+	}
+	if _, _, err := s.Trigger([]string{"booka"}); !errors.Is(err, ErrRefreshing) {
+		t.Errorf("Trigger during refresh = %v, want ErrRefreshing", err)
+	}
+	s.releaseRefresh("booka")
+	if _, _, err := s.Trigger([]string{"unknown"}); !errors.Is(err, ErrUnknownSite) {
+		t.Errorf("Trigger(unknown) = %v, want ErrUnknownSite", err)
+	}
+}
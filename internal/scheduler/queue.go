@@ -0,0 +1,229 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"betting-odds-scraper/internal/scraper"
+)
+
+// kickoffTiers set how often a fixture is refreshed by its time to kickoff,
+// since prices move most in the hours before a game starts
+var kickoffTiers = []struct {
+	within   time.Duration
+	interval time.Duration
+}{
+	{time.Hour, time.Minute},
+	{6 * time.Hour, 5 * time.Minute},
+	{48 * time.Hour, 10 * time.Minute},
+}
+
+// farInterval refreshes fixtures beyond the last kickoff tier
+const farInterval = 15 * time.Minute
+
+// refreshInterval returns how often a fixture kicking off in untilKickoff
+// should be refreshed
+func refreshInterval(untilKickoff time.Duration) time.Duration {
+	for _, tier := range kickoffTiers {
+		if untilKickoff <= tier.within {
+			return tier.interval
+		}
+	}
+	return farInterval
+}
+
+// QueueItem is one fixture at one site waiting for its next refresh
+type QueueItem struct {
+	SiteID          string    `json:"site_id"`
+	MatchID         string    `json:"match_id"`
+	SourceMatchID   string    `json:"source_match_id"`
+	HomeTeam        string    `json:"home_team"`
+	AwayTeam        string    `json:"away_team"`
+	League          string    `json:"league"`
+	Kickoff         time.Time `json:"kickoff"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	NextDue         time.Time `json:"next_due"`
+
+	interval time.Duration
+	index    int
+}
+
+// fixtureQueue is a min-heap of queue items ordered by when they are due
+type fixtureQueue []*QueueItem
+
+func (q fixtureQueue) Len() int { return len(q) }
+
+func (q fixtureQueue) Less(i, j int) bool { return q[i].NextDue.Before(q[j].NextDue) }
+
+func (q fixtureQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *fixtureQueue) Push(x interface{}) {
+	item := x.(*QueueItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *fixtureQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
+
+// priorityQueue schedules targeted refreshes of upcoming fixtures
+type priorityQueue struct {
+	manager *scraper.Manager
+	// claim marks a site in flight, failing when a job is scraping it, and
+	// release clears it again
+	claim   func(siteID string) bool
+	release func(siteID string)
+	items   fixtureQueue
+	byKey   map[string]*QueueItem
+	mutex   sync.Mutex
+}
+
+func newPriorityQueue(manager *scraper.Manager, claim func(siteID string) bool, release func(siteID string)) *priorityQueue {
+	return &priorityQueue{
+		manager: manager,
+		claim:   claim,
+		release: release,
+		byKey:   make(map[string]*QueueItem),
+	}
+}
+
+// sync adds newly seen fixtures to the queue, moves fixtures into a faster
+// tier as kickoff approaches and drops fixtures that have kicked off
+func (q *priorityQueue) sync(now time.Time) {
+	// Sites that can only be scraped in full stay on their own schedule
+	sites := make(map[string]bool)
+	for _, siteID := range q.manager.SiteIDs() {
+		sites[siteID] = q.manager.CanTarget(siteID)
+	}
+	matches := q.manager.UpcomingMatches()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		interval := refreshInterval(match.MatchTime.Sub(now))
+		for siteID, sourceID := range match.SourceIDs {
+			if !sites[siteID] {
+				continue
+			}
+			key := siteID + "|" + match.ID
+			seen[key] = true
+
+			item, exists := q.byKey[key]
+			if !exists {
+				item = &QueueItem{
+					SiteID:  siteID,
+					MatchID: match.ID,
+					NextDue: now.Add(interval),
+				}
+				heap.Push(&q.items, item)
+				q.byKey[key] = item
+			}
+			item.SourceMatchID = sourceID
+			item.HomeTeam = match.HomeTeam
+			item.AwayTeam = match.AwayTeam
+			item.League = match.League
+			item.Kickoff = match.MatchTime
+			item.interval = interval
+			item.IntervalSeconds = int(interval / time.Second)
+
+			// Entering a faster tier brings the next refresh forward
+			if item.NextDue.After(now.Add(interval)) {
+				item.NextDue = now.Add(interval)
+				heap.Fix(&q.items, item.index)
+			}
+		}
+	}
+
+	for key, item := range q.byKey {
+		if !seen[key] {
+			heap.Remove(&q.items, item.index)
+			delete(q.byKey, key)
+		}
+	}
+}
+
+// claimDue reschedules every item that is due and returns them grouped into
+// one target per site
+func (q *priorityQueue) claimDue(now time.Time) map[string]scraper.Target {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	targets := make(map[string]scraper.Target)
+	for len(q.items) > 0 && !q.items[0].NextDue.After(now) {
+		item := q.items[0]
+		target := targets[item.SiteID]
+		target.MatchIDs = append(target.MatchIDs, item.SourceMatchID)
+		targets[item.SiteID] = target
+
+		item.NextDue = now.Add(item.interval)
+		heap.Fix(&q.items, 0)
+	}
+	return targets
+}
+
+// snapshot returns up to limit queue items, soonest due first
+func (q *priorityQueue) snapshot(limit int) []QueueItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	items := make([]QueueItem, 0, len(q.items))
+	for _, item := range q.items {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].NextDue.Equal(items[j].NextDue) {
+			return items[i].NextDue.Before(items[j].NextDue)
+		}
+		return items[i].SiteID+items[i].MatchID < items[j].SiteID+items[j].MatchID
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// refreshDue scrapes every fixture whose refresh is due, one targeted scrape
+// per site
+func (q *priorityQueue) refreshDue() {
+	now := time.Now()
+	q.sync(now)
+	targets := q.claimDue(now)
+	if len(targets) == 0 {
+		return
+	}
+
+	// Each site's refresh is a single scrape, run alongside the others
+	ctx, cancel := context.WithTimeout(context.Background(), q.manager.ScrapeTimeout(1))
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for siteID, target := range targets {
+		// A full scrape already in progress refreshes these fixtures too
+		if !q.claim(siteID) {
+			continue
+		}
+		wg.Add(1)
+		go func(siteID string, target scraper.Target) {
+			defer wg.Done()
+			defer q.release(siteID)
+			log.Printf("Refreshing %d fixtures on %s", len(target.MatchIDs), siteID)
+			q.manager.ScrapeTargets(ctx, siteID, target)
+		}(siteID, target)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueueLiveSite(t *testing.T) {
+	now := time.Now()
+	manager := testManager(t)
+	registerSite(t, manager, "livebook", now.Add(30*time.Minute), nil)
+	results := manager.ScrapeSites(context.Background(), []string{"livebook"}, nil)
+	if results["livebook"].Error != "" {
+		t.Fatalf("scrape failed: %s", results["livebook"].Error)
+	}
+
+	q := newPriorityQueue(manager, func(string) bool { return true }, func(string) {})
+	q.sync(now)
+
+	// Only the API site can target, so the browser-only sites stay off the queue
+	items := q.snapshot(0)
+	if len(items) != 1 {
+		t.Fatalf("queued %d items, want 1: %+v", len(items), items)
+	}
+	item := items[0]
+	if item.SiteID != "livebook" || item.SourceMatchID != "livebook_1" {
+		t.Errorf("queued %s/%s, want livebook/livebook_1", item.SiteID, item.SourceMatchID)
+	}
+	// Kicking off within the hour, the fixture is refreshed every minute
+	if item.IntervalSeconds != 60 || !item.NextDue.Equal(now.Add(time.Minute)) {
+		t.Errorf("item due every %ds from %v, want every 60s from %v", item.IntervalSeconds, item.NextDue, now.Add(time.Minute))
+	}
+
+	if targets := q.claimDue(now); len(targets) != 0 {
+		t.Errorf("claimed %v before anything was due", targets)
+	}
+	targets := q.claimDue(now.Add(time.Minute))
+	if got := targets["livebook"].MatchIDs; len(got) != 1 || got[0] != "livebook_1" {
+		t.Fatalf("claimed %v, want livebook_1", targets)
+	}
+	result, ok := manager.ScrapeTargets(context.Background(), "livebook", targets["livebook"])
+	if !ok || result.MatchCount != 1 {
+		t.Errorf("targeted scrape returned %+v, %v; want one match", result, ok)
+	}
+}
+
+func TestQueueTiers(t *testing.T) {
+	now := time.Now()
+	manager := testManager(t)
+	registerSite(t, manager, "livebook", now.Add(3*time.Hour), nil)
+	manager.ScrapeSites(context.Background(), []string{"livebook"}, nil)
+
+	q := newPriorityQueue(manager, func(string) bool { return true }, func(string) {})
+	q.sync(now)
+	items := q.snapshot(0)
+	if len(items) != 1 || items[0].IntervalSeconds != 300 {
+		t.Fatalf("fixture 3h out queued as %+v, want one refreshed every 300s", items)
+	}
+
+	// Refreshed two hours later, the fixture is next due in five minutes,
+	// until it enters the last hour's tier and is due sooner
+	later := now.Add(2*time.Hour + 30*time.Second)
+	q.claimDue(later)
+	q.sync(later)
+	item := q.snapshot(0)[0]
+	if item.IntervalSeconds != 60 || !item.NextDue.Equal(later.Add(time.Minute)) {
+		t.Errorf("item due every %ds from %v, want every 60s from %v", item.IntervalSeconds, item.NextDue, later.Add(time.Minute))
+	}
+}
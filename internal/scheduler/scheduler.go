@@ -1,56 +1,96 @@
 package scheduler
 
 import (
-	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
+	"betting-odds-scraper/internal/config"
 	"betting-odds-scraper/internal/scraper"
 
 	"github.com/robfig/cron/v3"
 )
 
+// MinInterval is the shortest scrape interval a site can be scheduled at
+const MinInterval = 10 * time.Second
+
+// ErrUnknownSite is returned when scheduling a site that is not registered
+var ErrUnknownSite = errors.New("unknown site")
+
 type Scheduler struct {
-	cron    *cron.Cron
-	manager *scraper.Manager
+	cron      *cron.Cron
+	manager   *scraper.Manager
+	config    *config.Config
+	schedules map[string]*siteSchedule
+	queue     *priorityQueue
+	mutex     sync.Mutex
+
+	jobs      map[string]*Job
+	jobOrder  []string
+	inFlight  map[string]string
+	jobsMutex sync.Mutex
+}
+
+// siteSchedule is the cron entry currently scraping one site
+type siteSchedule struct {
+	entryID  cron.EntryID
+	interval time.Duration
+	lastRun  time.Time
 }
 
-func New(manager *scraper.Manager) *Scheduler {
+// Schedule describes when a site is scraped
+type Schedule struct {
+	SiteID          string    `json:"site_id"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	JitterSeconds   int       `json:"jitter_seconds"`
+	Default         bool      `json:"default"`
+	LastRun         time.Time `json:"last_run"`
+	NextRun         time.Time `json:"next_run"`
+}
+
+func New(manager *scraper.Manager, cfg *config.Config) *Scheduler {
 	c := cron.New(cron.WithSeconds())
-	return &Scheduler{
-		cron:    c,
-		manager: manager,
+	s := &Scheduler{
+		cron:      c,
+		manager:   manager,
+		config:    cfg,
+		schedules: make(map[string]*siteSchedule),
+		jobs:      make(map[string]*Job),
+		inFlight:  make(map[string]string),
 	}
+	s.queue = newPriorityQueue(manager, s.claimRefresh, s.releaseRefresh)
+	return s
 }
 
 func (s *Scheduler) Start() {
-	// Schedule scraping every 5 minutes
-	_, err := s.cron.AddFunc("0 */5 * * * *", func() {
-		log.Println("Starting scheduled scraping...")
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
-		
-		results := s.manager.ScrapeAll(ctx)
-		
-		successCount := 0
-		for _, result := range results {
-			if result.Success {
-				successCount++
-			}
+	// Schedule each site at its own interval, falling back to SCRAPE_INTERVAL
+	for _, siteID := range s.manager.SiteIDs() {
+		interval, exists := s.config.SiteSchedules[siteID]
+		if !exists {
+			interval = s.config.ScrapeInterval
 		}
-		
-		log.Printf("Scheduled scraping completed: %d/%d sites successful", successCount, len(results))
-	})
+		if _, err := s.SetSchedule(siteID, interval); err != nil {
+			log.Printf("Failed to schedule scraping for %s: %v", siteID, err)
+		}
+	}
 
-	if err != nil {
-		log.Printf("Failed to schedule scraping job: %v", err)
-		return
+	// Refresh fixtures more often as kickoff approaches
+	if s.config.AdaptiveScheduling {
+		refresh := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(s.queue.refreshDue))
+		if _, err := s.cron.AddJob("*/15 * * * * *", refresh); err != nil {
+			log.Printf("Failed to schedule fixture refresh job: %v", err)
+		}
 	}
 
 	// Schedule cleanup every hour
-	_, err = s.cron.AddFunc("0 0 * * * *", func() {
+	_, err := s.cron.AddFunc("0 0 * * * *", func() {
 		log.Println("Running cleanup tasks...")
-		// Add cleanup logic here if needed
+		removed := s.manager.PruneFixtures(time.Now().Add(-6 * time.Hour))
+		log.Printf("Cleanup completed: %d finished fixtures removed", removed)
 	})
 
 	if err != nil {
@@ -66,21 +106,152 @@ func (s *Scheduler) Stop() {
 	log.Println("Scheduler stopped")
 }
 
-func (s *Scheduler) TriggerScrape() {
-	go func() {
-		log.Println("Manual scraping triggered...")
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
-		
-		results := s.manager.ScrapeAll(ctx)
-		
-		successCount := 0
-		for _, result := range results {
-			if result.Success {
-				successCount++
-			}
+// SetSchedule (re)schedules a site to be scraped every interval, replacing
+// any existing schedule. A zero interval restores the configured default.
+func (s *Scheduler) SetSchedule(siteID string, interval time.Duration) (Schedule, error) {
+	if !s.knownSite(siteID) {
+		return Schedule{}, ErrUnknownSite
+	}
+	if interval == 0 {
+		interval = s.defaultInterval(siteID)
+	}
+	if interval < MinInterval {
+		return Schedule{}, fmt.Errorf("interval must be at least %s", MinInterval)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Skip a tick rather than stack scrapes when a site is slower than its interval
+	job := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+		s.scrapeSite(siteID)
+	}))
+	entryID := s.cron.Schedule(cron.Every(interval), job)
+
+	previous, exists := s.schedules[siteID]
+	if exists {
+		s.cron.Remove(previous.entryID)
+	}
+	schedule := &siteSchedule{entryID: entryID, interval: interval}
+	if exists {
+		schedule.lastRun = previous.lastRun
+	}
+	s.schedules[siteID] = schedule
+
+	log.Printf("Scheduled %s every %s", siteID, interval)
+	return s.describe(siteID, schedule), nil
+}
+
+// Schedules returns the current schedule of every site
+func (s *Scheduler) Schedules() []Schedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedules := make([]Schedule, 0, len(s.schedules))
+	for siteID, schedule := range s.schedules {
+		schedules = append(schedules, s.describe(siteID, schedule))
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].SiteID < schedules[j].SiteID
+	})
+	return schedules
+}
+
+// Queue returns up to limit upcoming fixture refreshes, soonest due first
+func (s *Scheduler) Queue(limit int) []QueueItem {
+	return s.queue.snapshot(limit)
+}
+
+// scrapeSite waits a random jitter so sites sharing an interval don't all
+// fire in the same second, then scrapes the site
+func (s *Scheduler) scrapeSite(siteID string) {
+	s.mutex.Lock()
+	schedule, exists := s.schedules[siteID]
+	if !exists {
+		s.mutex.Unlock()
+		return
+	}
+	jitter := s.jitter(schedule.interval)
+	s.mutex.Unlock()
+
+	if jitter > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(jitter))))
+	}
+
+	job, running, err := s.startJob([]string{siteID}, TriggerScheduled)
+	if errors.Is(err, ErrRefreshing) {
+		log.Printf("Skipping scheduled scraping of %s: fixture refresh in progress", siteID)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to start scheduled scraping of %s: %v", siteID, err)
+		return
+	}
+	if job == nil {
+		log.Printf("Skipping scheduled scraping of %s: already running in job %s", siteID, running[0].ID)
+		return
+	}
+	<-job.done
+
+	s.mutex.Lock()
+	schedule.lastRun = time.Now()
+	s.mutex.Unlock()
+}
+
+// claimRefresh marks a site in flight for an adaptive refresh, reporting
+// false when a job or another refresh is already scraping it
+func (s *Scheduler) claimRefresh(siteID string) bool {
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	if _, busy := s.inFlight[siteID]; busy {
+		return false
+	}
+	s.inFlight[siteID] = refreshHolder
+	return true
+}
+
+// releaseRefresh clears a site claimed by claimRefresh
+func (s *Scheduler) releaseRefresh(siteID string) {
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	if s.inFlight[siteID] == refreshHolder {
+		delete(s.inFlight, siteID)
+	}
+}
+
+// jitter is the configured jitter, capped at half the interval
+func (s *Scheduler) jitter(interval time.Duration) time.Duration {
+	if s.config.ScrapeJitter > interval/2 {
+		return interval / 2
+	}
+	return s.config.ScrapeJitter
+}
+
+func (s *Scheduler) defaultInterval(siteID string) time.Duration {
+	if interval, exists := s.config.SiteSchedules[siteID]; exists {
+		return interval
+	}
+	return s.config.ScrapeInterval
+}
+
+func (s *Scheduler) knownSite(siteID string) bool {
+	for _, id := range s.manager.SiteIDs() {
+		if id == siteID {
+			return true
 		}
-		
-		log.Printf("Manual scraping completed: %d/%d sites successful", successCount, len(results))
-	}()
-}
\ No newline at end of file
+	}
+	return false
+}
+
+func (s *Scheduler) describe(siteID string, schedule *siteSchedule) Schedule {
+	return Schedule{
+		SiteID:          siteID,
+		IntervalSeconds: int(schedule.interval / time.Second),
+		JitterSeconds:   int(s.jitter(schedule.interval) / time.Second),
+		Default:         schedule.interval == s.defaultInterval(siteID),
+		LastRun:         schedule.lastRun,
+		NextRun:         s.cron.Entry(schedule.entryID).Next,
+	}
+}
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/scraper"
+)
+
+// testManager builds a live mode manager in memory and without Chrome. Its
+// built-in sites scrape through Chrome alone, so they cannot be targeted.
+func testManager(t *testing.T) *scraper.Manager {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("MODE", config.ModeLive)
+	t.Setenv("STORE_DRIVER", "memory")
+	t.Setenv("SITE_APIS", "false")
+	t.Setenv("SITES_DIR", dir)
+	t.Setenv("ALIAS_FILE", filepath.Join(dir, "aliases.json"))
+	t.Setenv("ALIAS_DECISIONS_FILE", filepath.Join(dir, "alias_decisions.json"))
+	t.Setenv("TAX_PROFILES_FILE", filepath.Join(dir, "tax_profiles.json"))
+	t.Setenv("CRAWL_DELAY", "0")
+	t.Setenv("SCRAPE_RETRIES", "0")
+	manager := scraper.NewManager(config.New())
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+// registerSite adds an API site listing one fixture, <siteID>_1, kicking off
+// at kickoff. Its API answers once release is closed, or at once if nil.
+func registerSite(t *testing.T, manager *scraper.Manager, siteID string, kickoff time.Time, release chan struct{}) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if release != nil {
+			<-release
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	site := models.BettingSite{ID: siteID, Name: siteID, URL: server.URL, Active: true}
+	api := scraper.SiteAPI{
+		PageURL: func(page int) string { return server.URL },
+		Decode: func(body []byte, site models.BettingSite, now time.Time) ([]models.Match, []models.Odds, error) {
+			match := models.Match{ID: site.ID + "_1", HomeTeam: "Arsenal", AwayTeam: "Chelsea", Sport: "football", League: "Premier League", MatchTime: kickoff}
+			odds := models.Odds{ID: match.ID + "_odds", MatchID: match.ID, SiteID: site.ID, SiteName: site.Name, HomeWin: 2.1, Draw: 3.4, AwayWin: 3.6, ScrapedAt: now}
+			return []models.Match{match}, []models.Odds{odds}, nil
+		},
+	}
+	manager.RegisterScraper(scraper.NewAPIScraper(site, api, nil, time.Second))
+}
+
+func TestSetSchedule(t *testing.T) {
+	manager := testManager(t)
+	t.Setenv("SCRAPE_INTERVAL", "120")
+	t.Setenv("SCRAPE_JITTER", "30")
+	t.Setenv("SITE_SCHEDULES", "betika=20")
+	s := New(manager, config.New())
+
+	tests := []struct {
+		siteID       string
+		interval     time.Duration
+		wantInterval int
+		wantJitter   int
+		wantDefault  bool
+	}{
+		{"sportpesa", 5 * time.Minute, 300, 30, false},
+		// A zero interval restores the default
+		{"sportpesa", 0, 120, 30, true},
+		{"betika", 0, 20, 10, true},
+		// Jitter is capped at half the interval
+		{"sportpesa", 40 * time.Second, 40, 20, false},
+		{"sportpesa", MinInterval, 10, 5, false},
+	}
+	for _, tt := range tests {
+		schedule, err := s.SetSchedule(tt.siteID, tt.interval)
+		if err != nil {
+			t.Fatalf("SetSchedule(%s, %s): %v", tt.siteID, tt.interval, err)
+		}
+		if schedule.IntervalSeconds != tt.wantInterval || schedule.JitterSeconds != tt.wantJitter || schedule.Default != tt.wantDefault {
+			t.Errorf("SetSchedule(%s, %s) = %+v, want every %ds with %ds jitter, default %v", tt.siteID, tt.interval, schedule, tt.wantInterval, tt.wantJitter, tt.wantDefault)
+		}
+	}
+
+	// Rescheduling replaces the site's cron entry
+	if schedules := s.Schedules(); len(schedules) != 2 || len(s.cron.Entries()) != 2 {
+		t.Errorf("got %d schedules and %d cron entries, want 2 of each", len(schedules), len(s.cron.Entries()))
+	}
+	if _, err := s.SetSchedule("sportpesa", MinInterval-time.Second); err == nil {
+		t.Error("an interval below the minimum was accepted")
+	}
+	if _, err := s.SetSchedule("unknown", time.Minute); !errors.Is(err, ErrUnknownSite) {
+		t.Errorf("SetSchedule(unknown) = %v, want ErrUnknownSite", err)
+	}
+}
@@ -0,0 +1,273 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"betting-odds-scraper/internal/fixtures"
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/proxy"
+	"betting-odds-scraper/internal/ratelimit"
+)
+
+// maxAPIResponse bounds how much of an API response is read
+const maxAPIResponse = 10 << 20
+
+// browserUserAgent is sent with API requests so they look like the site's own
+// XHR calls, unless the scrape has a rotated browser profile
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// SiteAPI describes a bookmaker's internal JSON odds API
+type SiteAPI struct {
+	// Warmup is loaded once before the first API call to pick up the session
+	// cookies the site expects on its XHR requests
+	Warmup string
+	// PageURL returns the endpoint for one page of events, counting from 1
+	PageURL func(page int) string
+	// MaxPages stops paging; an empty page stops it sooner
+	MaxPages int
+	// Headers are sent with every API request
+	Headers map[string]string
+	// Decode maps one response page onto matches and odds
+	Decode DecodeFunc
+}
+
+// DecodeFunc maps one JSON response from a bookmaker onto matches and odds
+type DecodeFunc func(body []byte, site models.BettingSite, now time.Time) ([]models.Match, []models.Odds, error)
+
+// APIScraper reads odds straight from a bookmaker's JSON API, and falls back
+// to a browser scraper when the API fails
+type APIScraper struct {
+	siteInfo models.BettingSite
+	api      SiteAPI
+	client   *http.Client
+	fallback Scraper
+	warmed   atomic.Bool
+}
+
+// NewAPIScraper creates an API scraper for site. fallback may be nil, in which
+// case API errors are returned as they are.
+func NewAPIScraper(site models.BettingSite, api SiteAPI, fallback Scraper, timeout time.Duration) *APIScraper {
+	jar, _ := cookiejar.New(nil)
+	if api.MaxPages < 1 {
+		api.MaxPages = 1
+	}
+
+	return &APIScraper{
+		siteInfo: site,
+		api:      api,
+		client:   &http.Client{Timeout: timeout, Jar: jar},
+		fallback: fallback,
+	}
+}
+
+func (a *APIScraper) GetSiteInfo() models.BettingSite {
+	return a.siteInfo
+}
+
+func (a *APIScraper) ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error) {
+	matches, odds, err := a.FetchAPI(ctx)
+	if err == nil || a.fallback == nil {
+		return matches, odds, err
+	}
+
+	log.Printf("%s API failed, falling back to browser: %v", a.siteInfo.Name, err)
+	matches, odds, fallbackErr := a.fallback.ScrapeOdds(ctx)
+	if fallbackErr != nil {
+		return nil, nil, fmt.Errorf("api: %w; browser: %w", err, fallbackErr)
+	}
+	return matches, odds, nil
+}
+
+// ScrapeTargets fetches the site in full, as the API has no per-fixture
+// calls, and keeps only the targeted fixtures and leagues
+func (a *APIScraper) ScrapeTargets(ctx context.Context, target Target) ([]models.Match, []models.Odds, error) {
+	matches, odds, err := a.ScrapeOdds(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches, odds = target.narrow(matches, odds)
+	return matches, odds, nil
+}
+
+// FetchAPI reads every page of events from the API without falling back
+func (a *APIScraper) FetchAPI(ctx context.Context) ([]models.Match, []models.Odds, error) {
+	// Replays have no use for session cookies
+	if a.api.Warmup != "" && !a.warmed.Load() && !fixtures.FromContext(ctx).Replaying() {
+		// Cookies are a courtesy; the API call below reports real failures
+		if _, err := a.get(ctx, a.api.Warmup, nil); err != nil {
+			log.Printf("%s warmup failed: %v", a.siteInfo.Name, err)
+		} else {
+			a.warmed.Store(true)
+		}
+	}
+
+	var matches []models.Match
+	var odds []models.Odds
+	var seen map[string]bool
+	for page := 1; page <= a.api.MaxPages; page++ {
+		url := a.api.PageURL(page)
+		body, err := fixtures.Fetch(ctx, fixtures.KindAPI, url, func() ([]byte, error) {
+			return a.get(ctx, url, a.api.Headers)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		pageMatches, pageOdds, err := a.api.Decode(body, a.siteInfo, fixtures.Now(ctx))
+		if err != nil {
+			return nil, nil, classified(models.ErrorParse, fmt.Errorf("failed to decode %s page %d: %w", a.siteInfo.Name, page, err))
+		}
+		if len(pageMatches) == 0 {
+			break
+		}
+
+		matches, odds, seen = appendNew(matches, odds, seen, pageMatches, pageOdds)
+	}
+
+	if len(matches) == 0 {
+		return nil, nil, fmt.Errorf("%s API: %w", a.siteInfo.Name, errNoEvents)
+	}
+	log.Printf("%s: Found %d matches with odds via API", a.siteInfo.Name, len(matches))
+	matches, odds = stamp(matches, odds, models.ProvenanceAPI)
+	return matches, odds, nil
+}
+
+// get fetches a URL with browser-like headers and returns the body of a
+// successful response
+func (a *APIScraper) get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	limiter := ratelimit.FromContext(ctx)
+	if err := limiter.Wait(ctx, url); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	userAgent, acceptLanguage := browserUserAgent, "en-US,en;q=0.9"
+	client := a.client
+	identity, _ := proxy.FromContext(ctx)
+	if identity.Profile.UserAgent != "" {
+		userAgent, acceptLanguage = identity.Profile.UserAgent, identity.Profile.AcceptLanguage
+	}
+	if identity.Proxy != nil {
+		// Session cookies are kept across proxies, as a roaming browser would
+		proxied := *a.client
+		proxied.Transport = identity.Proxy.Transport()
+		client = &proxied
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+	req.Header.Set("Accept-Language", acceptLanguage)
+	req.Header.Set("Origin", a.siteInfo.URL)
+	req.Header.Set("Referer", a.siteInfo.URL+"/")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		limiter.Backoff(url, retryAfter(resp.Header.Get("Retry-After")))
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Bot protection often sends its challenge page with a 403
+		if resp.StatusCode == http.StatusForbidden && errors.Is(blockedPage(string(body)), errCaptcha) {
+			return nil, fmt.Errorf("%s: %w", url, errCaptcha)
+		}
+		return nil, &statusError{URL: url, Code: resp.StatusCode, Status: resp.Status}
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		if err := blockedPage(string(body)); err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+	}
+	return body, nil
+}
+
+// retryAfter reads a Retry-After header given in seconds, as bookmakers send
+// it, or returns 0
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// statusError is an unsuccessful HTTP response from a site
+type statusError struct {
+	URL    string
+	Code   int
+	Status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned %s", e.URL, e.Status)
+}
+
+// appendNew appends matches and their odds not already seen, since pages and
+// responses can overlap, and returns the updated set of seen match IDs
+func appendNew(matches []models.Match, odds []models.Odds, seen map[string]bool, newMatches []models.Match, newOdds []models.Odds) ([]models.Match, []models.Odds, map[string]bool) {
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	for i, match := range newMatches {
+		if seen[match.ID] {
+			continue
+		}
+		seen[match.ID] = true
+		matches = append(matches, match)
+		odds = append(odds, newOdds[i])
+	}
+	return matches, odds, seen
+}
+
+// apiPrice is a price that APIs send either as a number or a quoted string
+type apiPrice float64
+
+func (p *apiPrice) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*p = 0
+		return nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid price %s", data)
+	}
+	*p = apiPrice(price)
+	return nil
+}
+
+// decodeJSON unmarshals an API body, naming the start of the body in errors
+// since sites answer blocked requests with HTML
+func decodeJSON(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		preview := strings.TrimSpace(string(body))
+		if len(preview) > 60 {
+			preview = preview[:60] + "..."
+		}
+		return fmt.Errorf("%w (body starts %q)", err, preview)
+	}
+	return nil
+}
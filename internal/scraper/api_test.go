@@ -0,0 +1,191 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// fixtureServer serves a recorded API response for the first page and an
+// empty list for any later page
+func fixtureServer(t *testing.T, fixture, empty string) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "api", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") + r.URL.Query().Get("pag_min") {
+		case "1":
+			w.Write(body)
+		default:
+			w.Write([]byte(empty))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBetikaAPI(t *testing.T) {
+	server := fixtureServer(t, "betika_matches.json", `{"data":[]}`)
+	site := NewBetikaScraper(nil, "").GetSiteInfo()
+
+	matches, odds, err := NewAPIScraper(site, BetikaAPI(server.URL), nil, time.Second).ScrapeOdds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The suspended event has no prices and is skipped
+	if len(matches) != 2 || len(odds) != 2 {
+		t.Fatalf("got %d matches and %d odds, want 2 and 2", len(matches), len(odds))
+	}
+
+	match := matches[0]
+	if match.ID != "betika_4012345" || match.HomeTeam != "Arsenal" || match.League != "Premier League" {
+		t.Errorf("unexpected match %+v", match)
+	}
+	if want := time.Date(2026, 10, 18, 14, 30, 0, 0, time.UTC); !match.MatchTime.Equal(want) {
+		t.Errorf("kickoff %v, want %v", match.MatchTime, want)
+	}
+
+	checkPrices(t, odds[0], map[models.Price]float64{
+		{Market: models.MarketMatchResult, Period: models.PeriodFullTime, Selection: models.SelectionHome}:            2.10,
+		{Market: models.MarketMatchResult, Period: models.PeriodFullTime, Selection: models.SelectionDraw}:            3.40,
+		{Market: models.MarketOverUnder, Line: "2.5", Period: models.PeriodFullTime, Selection: models.SelectionOver}: 1.85,
+		{Market: models.MarketBTTS, Period: models.PeriodFullTime, Selection: models.SelectionNo}:                     2.05,
+	})
+}
+
+func TestBetikaTotalsLine(t *testing.T) {
+	body := `{"data":[{"match_id":"1","home_team":"Arsenal","away_team":"Chelsea","start_time":"2026-10-18 17:30:00","competition_name":"Premier League","odds":[
+		{"sub_type_id":"18","odds":[
+			{"odd_key":"over 2.50","odd_value":"1.85","special_bet_value":"total=2.50"},
+			{"odd_key":"under 3.5","odd_value":"1.40","special_bet_value":"3.5"},
+			{"odd_key":"over","odd_value":"2.20","special_bet_value":"total="}
+		]}
+	]}]}`
+	site := NewBetikaScraper(nil, "").GetSiteInfo()
+	_, odds, err := decodeBetika([]byte(body), site, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(odds) != 1 {
+		t.Fatalf("got %d odds, want 1", len(odds))
+	}
+
+	// Lines are written the way every site writes them, and a line that
+	// does not parse drops its price
+	checkPrices(t, odds[0], map[models.Price]float64{
+		{Market: models.MarketOverUnder, Line: "2.5", Period: models.PeriodFullTime, Selection: models.SelectionOver}:  1.85,
+		{Market: models.MarketOverUnder, Line: "3.5", Period: models.PeriodFullTime, Selection: models.SelectionUnder}: 1.40,
+	})
+	if len(odds[0].Markets) != 2 {
+		t.Errorf("kept %d prices, want 2: %+v", len(odds[0].Markets), odds[0].Markets)
+	}
+}
+
+func TestSportPesaAPI(t *testing.T) {
+	server := fixtureServer(t, "sportpesa_games.json", `[]`)
+	site := NewSportPesaScraper(nil, "").GetSiteInfo()
+
+	matches, odds, err := NewAPIScraper(site, SportPesaAPI(server.URL), nil, time.Second).ScrapeOdds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || len(odds) != 2 {
+		t.Fatalf("got %d matches and %d odds, want 2 and 2", len(matches), len(odds))
+	}
+	if match := matches[1]; match.ID != "sportpesa_7734590" || match.AwayTeam != "Kenya Police" {
+		t.Errorf("unexpected match %+v", match)
+	}
+
+	checkPrices(t, odds[0], map[models.Price]float64{
+		{Market: models.MarketMatchResult, Period: models.PeriodFullTime, Selection: models.SelectionAway}:             3.25,
+		{Market: models.MarketOverUnder, Line: "2.5", Period: models.PeriodFullTime, Selection: models.SelectionUnder}: 1.92,
+		{Market: models.MarketBTTS, Period: models.PeriodFullTime, Selection: models.SelectionYes}:                     1.72,
+	})
+	// Numeric prices decode as well as quoted ones
+	checkPrices(t, odds[1], map[models.Price]float64{
+		{Market: models.MarketMatchResult, Period: models.PeriodFullTime, Selection: models.SelectionHome}: 2.3,
+	})
+}
+
+func TestAPIScrapeTargets(t *testing.T) {
+	server := fixtureServer(t, "sportpesa_games.json", `[]`)
+	site := NewSportPesaScraper(nil, "").GetSiteInfo()
+	scraper := NewAPIScraper(site, SportPesaAPI(server.URL), nil, time.Second)
+
+	// The API is fetched in full and narrowed to the target
+	matches, odds, err := scraper.ScrapeTargets(context.Background(), Target{MatchIDs: []string{"sportpesa_7734590"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].ID != "sportpesa_7734590" || len(odds) != 1 || odds[0].MatchID != "sportpesa_7734590" {
+		t.Fatalf("got %+v and %+v, want only sportpesa_7734590", matches, odds)
+	}
+
+	matches, _, err = scraper.ScrapeTargets(context.Background(), Target{Leagues: []string{"england - premier league"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].League != "England - Premier League" {
+		t.Errorf("league target returned %+v", matches)
+	}
+}
+
+// stubScraper returns fixed results, standing in for the browser fallback
+type stubScraper struct {
+	matches []models.Match
+	odds    []models.Odds
+	err     error
+	calls   int
+}
+
+func (s *stubScraper) GetSiteInfo() models.BettingSite { return models.BettingSite{ID: "stub"} }
+
+func (s *stubScraper) ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error) {
+	s.calls++
+	return s.matches, s.odds, s.err
+}
+
+func TestAPIScraperFallback(t *testing.T) {
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>Access denied</html>", http.StatusForbidden)
+	}))
+	defer blocked.Close()
+	site := NewBetikaScraper(nil, "").GetSiteInfo()
+
+	fallback := &stubScraper{matches: []models.Match{{ID: "betika_browser"}}, odds: []models.Odds{{MatchID: "betika_browser"}}}
+	matches, _, err := NewAPIScraper(site, BetikaAPI(blocked.URL), fallback, time.Second).ScrapeOdds(context.Background())
+	if err != nil || fallback.calls != 1 || len(matches) != 1 || matches[0].ID != "betika_browser" {
+		t.Fatalf("fallback not used: matches %v, err %v, calls %d", matches, err, fallback.calls)
+	}
+
+	failing := &stubScraper{err: errors.New("chrome not found")}
+	if _, _, err := NewAPIScraper(site, BetikaAPI(blocked.URL), failing, time.Second).ScrapeOdds(context.Background()); err == nil {
+		t.Fatal("expected an error when the API and the browser both fail")
+	}
+
+	// Without a fallback the API error is returned as it is
+	if _, _, err := NewAPIScraper(site, BetikaAPI(blocked.URL), nil, time.Second).ScrapeOdds(context.Background()); err == nil {
+		t.Fatal("expected the API error")
+	}
+}
+
+func checkPrices(t *testing.T, odd models.Odds, want map[models.Price]float64) {
+	t.Helper()
+	for price, value := range want {
+		got, ok := odd.Price(price.Market, price.Line, price.Period, price.Selection)
+		if !ok || got != value {
+			t.Errorf("%s %s: got %v (present %v), want %v", odd.MatchID, price.Key(), got, ok, value)
+		}
+	}
+}
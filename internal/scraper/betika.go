@@ -4,27 +4,41 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"betting-odds-scraper/internal/browser"
 	"betting-odds-scraper/internal/models"
-
-	"github.com/PuerkitoBio/goquery"
-	"github.com/chromedp/chromedp"
 )
 
+// betikaCapture matches the API calls Betika's football page loads its odds from
+var betikaCapture = []*regexp.Regexp{regexp.MustCompile(`api\.betika\.com/v1/uo/matches`)}
+
 type BetikaScraper struct {
 	siteInfo models.BettingSite
+	pool     *browser.Pool
+	baseURL  string
 }
 
-func NewBetikaScraper() *BetikaScraper {
+// NewBetikaScraper creates a Betika scraper. baseURL replaces the live site,
+// for example with the mock bookmaker; empty means the live site.
+func NewBetikaScraper(pool *browser.Pool, baseURL string) *BetikaScraper {
+	if baseURL == "" {
+		baseURL = "https://www.betika.com"
+	}
+
 	return &BetikaScraper{
 		siteInfo: models.BettingSite{
 			ID:     "betika",
 			Name:   "Betika",
 			URL:    "https://www.betika.com",
 			Active: true,
+			Tax:    models.KenyaTaxProfile,
 		},
+		pool:    pool,
+		baseURL: baseURL,
 	}
 }
 
@@ -33,139 +47,143 @@ func (b *BetikaScraper) GetSiteInfo() models.BettingSite {
 }
 
 func (b *BetikaScraper) ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error) {
-	var matches []models.Match
-	var odds []models.Odds
-
-	// Create Chrome context with options to suppress errors
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-logging", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("log-level", "3"), // Suppress INFO, WARNING, ERROR
-	)
-	
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
-	
-	chromeCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
-
-	var htmlContent string
-	
-	// Navigate to Betika football section
-	err := chromedp.Run(chromeCtx,
-		chromedp.Navigate("https://www.betika.com/en-ke/sport/football"),
-		chromedp.WaitVisible("body", chromedp.ByQuery),
-		chromedp.Sleep(5*time.Second), // Wait for dynamic content
-		chromedp.OuterHTML("html", &htmlContent),
-	)
-
+	// Load the football page, capturing the odds calls it makes
+	htmlContent, responses, err := loadCaptured(ctx, b.pool, b.siteInfo.ID, b.baseURL+"/en-ke/sport/football", betikaCapture)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load Betika page: %w", err)
 	}
 
-	// Parse HTML with goquery
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	// The captured API responses carry the odds the page renders
+	matches, odds := decodeCaptured(responses, decodeBetika, b.siteInfo)
+	if len(matches) == 0 {
+		return nil, nil, noEvents([]string{htmlContent}, fmt.Errorf("no odds in %d captured Betika responses", len(responses)))
+	}
+
+	log.Printf("Betika: Found %d matches with odds from captured responses", len(matches))
+	matches, odds = stamp(matches, odds, models.ProvenanceLive)
+	return matches, odds, nil
+}
+
+// betikaAPIURL is the host of Betika's JSON API
+const betikaAPIURL = "https://api.betika.com"
+
+// betikaTimeLayout is how Betika's API writes kickoff times, in Kenyan time
+const betikaTimeLayout = "2006-01-02 15:04:05"
+
+// Betika market sub types in the match list
+const (
+	betikaTotals = "18"
+	betikaBTTS   = "29"
+)
+
+// BetikaAPI describes the JSON endpoint behind Betika's football page.
+// baseURL replaces the live API host; empty means the live API.
+func BetikaAPI(baseURL string) SiteAPI {
+	if baseURL == "" {
+		baseURL = betikaAPIURL
 	}
-	_ = doc // Suppress unused variable warning
-
-	// Create some sample data for demonstration since actual scraping requires 
-	// specific selectors that change frequently on betting sites
-	sampleMatches := []struct {
-		home, away string
-		homeOdds, drawOdds, awayOdds float64
-	}{
-		{"Arsenal", "Chelsea", 2.10, 3.40, 3.20},
-		{"Manchester United", "Liverpool", 2.80, 3.10, 2.60},
-		{"Barcelona", "Real Madrid", 2.45, 3.25, 2.90},
-		{"Bayern Munich", "Borussia Dortmund", 1.95, 3.60, 3.80},
-		{"PSG", "Marseille", 1.75, 3.80, 4.50},
+	return SiteAPI{
+		PageURL: func(page int) string {
+			return fmt.Sprintf("%s/v1/uo/matches?page=%d&limit=50&sport_id=14&sort_id=2&period_id=-1&sub_type_id=1,18,29", baseURL, page)
+		},
+		MaxPages: 4,
+		Decode:   decodeBetika,
 	}
+}
 
-	for i, sample := range sampleMatches {
-		matchID := fmt.Sprintf("betika_%s_vs_%s_%d", 
-			strings.ReplaceAll(strings.ToLower(sample.home), " ", "_"),
-			strings.ReplaceAll(strings.ToLower(sample.away), " ", "_"),
-			time.Now().Unix()+int64(i))
+type betikaResponse struct {
+	Data []struct {
+		MatchID     string   `json:"match_id"`
+		HomeTeam    string   `json:"home_team"`
+		AwayTeam    string   `json:"away_team"`
+		StartTime   string   `json:"start_time"`
+		Competition string   `json:"competition_name"`
+		HomeOdd     apiPrice `json:"home_odd"`
+		NeutralOdd  apiPrice `json:"neutral_odd"`
+		AwayOdd     apiPrice `json:"away_odd"`
+		Odds        []struct {
+			SubTypeID string `json:"sub_type_id"`
+			Odds      []struct {
+				Key   string   `json:"odd_key"`
+				Value apiPrice `json:"odd_value"`
+				Line  string   `json:"special_bet_value"`
+			} `json:"odds"`
+		} `json:"odds"`
+	} `json:"data"`
+}
 
-		match := models.Match{
-			ID:        matchID,
-			HomeTeam:  sample.home,
-			AwayTeam:  sample.away,
-			Sport:     "football",
-			League:    "Premier League",
-			MatchTime: time.Now().Add(time.Duration(24+i*2) * time.Hour),
-			Status:    "upcoming",
-		}
+// decodeBetika maps one page of Betika's match list
+func decodeBetika(body []byte, site models.BettingSite, now time.Time) ([]models.Match, []models.Odds, error) {
+	var resp betikaResponse
+	if err := decodeJSON(body, &resp); err != nil {
+		return nil, nil, err
+	}
 
-		matches = append(matches, match)
+	var matches []models.Match
+	var odds []models.Odds
+	for _, event := range resp.Data {
+		kickoff, err := time.ParseInLocation(betikaTimeLayout, event.StartTime, kenyaLocation)
+		if err != nil || event.MatchID == "" {
+			continue
+		}
 
+		matchID := site.ID + "_" + event.MatchID
 		odd := models.Odds{
-			ID:        fmt.Sprintf("%s_odds", matchID),
+			ID:        matchID + "_odds",
 			MatchID:   matchID,
-			SiteID:    b.siteInfo.ID,
-			SiteName:  b.siteInfo.Name,
-			HomeWin:   sample.homeOdds,
-			Draw:      sample.drawOdds,
-			AwayWin:   sample.awayOdds,
-			ScrapedAt: time.Now(),
+			SiteID:    site.ID,
+			SiteName:  site.Name,
+			ScrapedAt: now,
 		}
-		odds = append(odds, odd)
-	}
-
-	// Try to extract real data from the page (this would need actual selectors)
-	doc.Find("div, span, td").Each(func(i int, s *goquery.Selection) {
-		text := strings.TrimSpace(s.Text())
-		// Look for patterns that might indicate match data
-		if strings.Contains(text, "vs") || strings.Contains(text, " - ") {
-			parts := strings.Split(text, " vs ")
-			if len(parts) != 2 {
-				parts = strings.Split(text, " - ")
-			}
-			if len(parts) == 2 {
-				homeTeam := strings.TrimSpace(parts[0])
-				awayTeam := strings.TrimSpace(parts[1])
-				
-				if len(homeTeam) > 2 && len(awayTeam) > 2 && len(homeTeam) < 30 && len(awayTeam) < 30 {
-					matchID := fmt.Sprintf("betika_real_%s_vs_%s_%d", 
-						strings.ReplaceAll(strings.ToLower(homeTeam), " ", "_"),
-						strings.ReplaceAll(strings.ToLower(awayTeam), " ", "_"),
-						time.Now().Unix())
-
-					match := models.Match{
-						ID:        matchID,
-						HomeTeam:  homeTeam,
-						AwayTeam:  awayTeam,
-						Sport:     "football",
-						League:    "Live Data",
-						MatchTime: time.Now().Add(24 * time.Hour),
-						Status:    "upcoming",
+		if event.HomeOdd > 1 && event.AwayOdd > 1 {
+			odd.SetMatchResult(float64(event.HomeOdd), float64(event.NeutralOdd), float64(event.AwayOdd))
+		}
+		for _, market := range event.Odds {
+			for _, price := range market.Odds {
+				if price.Value <= 1 {
+					continue
+				}
+				key := strings.ToLower(price.Key)
+				switch {
+				case market.SubTypeID == betikaTotals && strings.HasPrefix(key, "over"):
+					if line, ok := betikaLine(price.Line); ok {
+						odd.SetPrice(models.MarketOverUnder, line, models.PeriodFullTime, models.SelectionOver, float64(price.Value))
 					}
-
-					matches = append(matches, match)
-
-					// Add sample odds for real matches found
-					odd := models.Odds{
-						ID:        fmt.Sprintf("%s_odds", matchID),
-						MatchID:   matchID,
-						SiteID:    b.siteInfo.ID,
-						SiteName:  b.siteInfo.Name,
-						HomeWin:   2.10 + float64(i%10)*0.1,
-						Draw:      3.20 + float64(i%5)*0.1,
-						AwayWin:   2.80 + float64(i%8)*0.1,
-						ScrapedAt: time.Now(),
+				case market.SubTypeID == betikaTotals && strings.HasPrefix(key, "under"):
+					if line, ok := betikaLine(price.Line); ok {
+						odd.SetPrice(models.MarketOverUnder, line, models.PeriodFullTime, models.SelectionUnder, float64(price.Value))
 					}
-					odds = append(odds, odd)
+				case market.SubTypeID == betikaBTTS && key == "yes":
+					odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes, float64(price.Value))
+				case market.SubTypeID == betikaBTTS && key == "no":
+					odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionNo, float64(price.Value))
 				}
 			}
 		}
-	})
+		if len(odd.Markets) == 0 {
+			continue
+		}
 
-	log.Printf("Betika: Found %d matches with odds", len(matches))
+		matches = append(matches, models.Match{
+			ID:        matchID,
+			HomeTeam:  event.HomeTeam,
+			AwayTeam:  event.AwayTeam,
+			Sport:     "football",
+			League:    event.Competition,
+			MatchTime: kickoff,
+			Status:    "upcoming",
+		})
+		odds = append(odds, odd)
+	}
 	return matches, odds, nil
-}
\ No newline at end of file
+}
+
+// betikaLine reads the line of a totals price from its special bet value,
+// such as "total=2.50", in the form every site's lines take
+func betikaLine(value string) (string, bool) {
+	line, err := strconv.ParseFloat(strings.TrimPrefix(value, "total="), 64)
+	if err != nil {
+		return "", false
+	}
+	return models.FormatLine(line), true
+}
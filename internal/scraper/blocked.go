@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// captchaMarkers are found in the challenge pages of the captcha and bot
+// protection services bookmakers use
+var captchaMarkers = []string{
+	"g-recaptcha",
+	"www.google.com/recaptcha",
+	"h-captcha",
+	"hcaptcha.com",
+	"cf-challenge",
+	"challenges.cloudflare.com",
+	"cf-turnstile",
+	"captcha-delivery.com",
+	"px-captcha",
+}
+
+// blockedTitles are the titles of access denied pages from bot protection
+// services and web servers
+var blockedTitles = []string{
+	"403 forbidden",
+	"access denied",
+	"attention required! | cloudflare",
+	"just a moment...",
+	"request blocked",
+	"you have been blocked",
+}
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// blockedPage reports whether a page is a captcha or access denied page
+// rather than the site's content, returning errCaptcha or errBlocked
+func blockedPage(html string) error {
+	lower := strings.ToLower(html)
+	for _, marker := range captchaMarkers {
+		if strings.Contains(lower, marker) {
+			return errCaptcha
+		}
+	}
+
+	match := titlePattern.FindStringSubmatch(lower)
+	if match == nil {
+		return nil
+	}
+	title := strings.TrimSpace(match[1])
+	for _, blocked := range blockedTitles {
+		if strings.HasPrefix(title, blocked) {
+			return errBlocked
+		}
+	}
+	return nil
+}
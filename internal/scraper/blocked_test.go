@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/proxy"
+)
+
+func TestBlockedPage(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want error
+	}{
+		{"odds", `<html><head><title>Betika | Football</title></head><body><div class="prebet-match"></div></body></html>`, nil},
+		{"recaptcha", `<html><body><div class="g-recaptcha" data-sitekey="x"></div></body></html>`, errCaptcha},
+		{"turnstile", `<html><script src="https://challenges.cloudflare.com/turnstile/v0/api.js"></script></html>`, errCaptcha},
+		{"cloudflare", `<html><head><title>Attention Required! | Cloudflare</title></head></html>`, errBlocked},
+		{"forbidden", "<html><head>\n<TITLE>403 Forbidden</TITLE>\n</head></html>", errBlocked},
+	}
+	for _, tt := range tests {
+		if got := blockedPage(tt.html); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAPIUsesIdentity(t *testing.T) {
+	var userAgent, language string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent, language = r.Header.Get("User-Agent"), r.Header.Get("Accept-Language")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<html><div class="h-captcha"></div></html>`))
+	}))
+	defer server.Close()
+
+	profile := proxy.Profile{UserAgent: "Mozilla/5.0 (X11; Linux x86_64) Test", AcceptLanguage: "en-KE,en;q=0.9"}
+	ctx := proxy.NewContext(context.Background(), proxy.Identity{Profile: profile})
+	site := NewBetikaScraper(nil, "").GetSiteInfo()
+	_, _, err := NewAPIScraper(site, BetikaAPI(server.URL), nil, time.Second).ScrapeOdds(ctx)
+
+	if !errors.Is(err, errCaptcha) {
+		t.Errorf("got %v, want a captcha", err)
+	}
+	if userAgent != profile.UserAgent || language != profile.AcceptLanguage {
+		t.Errorf("sent %q and %q, want the identity's profile", userAgent, language)
+	}
+}
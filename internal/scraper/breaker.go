@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// maxCooldownDoublings caps how far failed probes stretch a breaker's cooldown
+const maxCooldownDoublings = 4
+
+// ErrCircuitOpen is returned instead of scraping a site whose breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Breaker stops scraping a site after repeated failed scrapes. Once its
+// cooldown passes a single probe scrape is let through: success closes the
+// breaker, failure opens it again for twice as long.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mutex     sync.Mutex
+	state     string
+	failures  int
+	probes    int
+	trips     int
+	openedAt  time.Time
+	nextProbe time.Time
+	probing   bool
+}
+
+// BreakerStatus is a snapshot of a breaker for the API and metrics
+type BreakerStatus struct {
+	State     string     `json:"state"`
+	Failures  int        `json:"consecutive_failures"`
+	Trips     int        `json:"trips"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	NextProbe *time.Time `json:"next_probe,omitempty"`
+}
+
+// NewBreaker creates a breaker that opens after threshold consecutive
+// failures. A threshold below 1 disables it.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed}
+}
+
+// Allow reports whether a scrape may run now, turning an open breaker whose
+// cooldown has passed half open for one probe
+func (b *Breaker) Allow(now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Before(b.nextProbe) {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// Record updates the breaker with the outcome of a scrape it allowed
+func (b *Breaker) Record(success bool, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probes = 0
+		return
+	}
+
+	b.failures++
+	switch {
+	case b.state == BreakerHalfOpen:
+		if b.probes < maxCooldownDoublings {
+			b.probes++
+		}
+		b.open(now)
+	case b.threshold > 0 && b.failures >= b.threshold:
+		b.trips++
+		b.open(now)
+	}
+}
+
+// open opens the breaker; the caller must hold the lock
+func (b *Breaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.nextProbe = now.Add(b.cooldown << b.probes)
+}
+
+// Status returns the breaker's current state
+func (b *Breaker) Status() BreakerStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	status := BreakerStatus{State: b.state, Failures: b.failures, Trips: b.trips}
+	if b.state != BreakerClosed {
+		openedAt, nextProbe := b.openedAt, b.nextProbe
+		status.OpenedAt = &openedAt
+		status.NextProbe = &nextProbe
+	}
+	return status
+}
@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if !breaker.Allow(now) {
+			t.Fatalf("closed breaker refused scrape %d", i+1)
+		}
+		breaker.Record(false, now)
+	}
+	if status := breaker.Status(); status.State != BreakerOpen || status.Trips != 1 {
+		t.Fatalf("got %+v after 3 failures, want open", status)
+	}
+	if breaker.Allow(now.Add(30 * time.Second)) {
+		t.Fatal("open breaker allowed a scrape during its cooldown")
+	}
+
+	// One probe after the cooldown; a failed probe doubles the cooldown
+	probeAt := now.Add(time.Minute)
+	if !breaker.Allow(probeAt) || breaker.Allow(probeAt) {
+		t.Fatal("want exactly one probe after the cooldown")
+	}
+	breaker.Record(false, probeAt)
+	if next := breaker.Status().NextProbe; next == nil || !next.Equal(probeAt.Add(2*time.Minute)) {
+		t.Fatalf("next probe %v, want %v", next, probeAt.Add(2*time.Minute))
+	}
+
+	// A successful probe closes it
+	probeAt = probeAt.Add(2 * time.Minute)
+	if !breaker.Allow(probeAt) {
+		t.Fatal("second probe refused")
+	}
+	breaker.Record(true, probeAt)
+	if status := breaker.Status(); status.State != BreakerClosed || status.Failures != 0 || status.NextProbe != nil {
+		t.Fatalf("got %+v after a successful probe, want closed", status)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Retries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second} {
+		if got := policy.Delay(retry); got != want {
+			t.Errorf("retry %d waits %v, want %v", retry, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("jittered delay %v outside 0.5s-1.5s", got)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	policy := RetryPolicy{Retries: 3, BaseDelay: time.Second, MaxDelay: 3 * time.Second, Jitter: 0.5}
+	// Four 30s attempts and waits of at most 1.5s, 3s and 4.5s
+	if got, want := policy.Budget(30*time.Second), 129*time.Second; got != want {
+		t.Errorf("budget %v, want %v", got, want)
+	}
+	if got := (RetryPolicy{}).Budget(30 * time.Second); got != 30*time.Second {
+		t.Errorf("budget without retries %v, want 30s", got)
+	}
+}
+
+func TestAPIStatusErrorIsRetryable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	site := NewBetikaScraper(nil, "").GetSiteInfo()
+	_, _, err := NewAPIScraper(site, BetikaAPI(server.URL), nil, time.Second).ScrapeOdds(context.Background())
+	if !retryable(Kind(err)) {
+		t.Fatalf("503 from the API should be retried: %v", err)
+	}
+}
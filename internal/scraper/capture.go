@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"betting-odds-scraper/internal/browser"
+	"betting-odds-scraper/internal/fixtures"
+	"betting-odds-scraper/internal/models"
+
+	"github.com/chromedp/chromedp"
+)
+
+// captureWait bounds how long a page may take to request its odds
+const captureWait = 15 * time.Second
+
+// captureQuiet is how long a page's odds requests must have settled before
+// the page is read
+const captureQuiet = 750 * time.Millisecond
+
+// loadCaptured opens url in a pooled tab and returns its HTML together with
+// the JSON responses matching the site's capture patterns that arrived while
+// it loaded. Without patterns nothing is captured. Record and replay sessions
+// record or stand in for both.
+func loadCaptured(ctx context.Context, pool *browser.Pool, siteID, url string, patterns []*regexp.Regexp) (html string, responses []browser.Response, err error) {
+	session := fixtures.FromContext(ctx)
+	if session.Replaying() {
+		page, err := session.Next(fixtures.KindPage)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, artifact := range session.Drain(fixtures.KindCapture) {
+			responses = append(responses, browser.Response{URL: artifact.URL, Body: artifact.Body, CapturedAt: fixtures.Now(ctx)})
+		}
+		return string(page.Body), responses, nil
+	}
+
+	tab, err := pool.NewTab(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open browser tab: %w", err)
+	}
+	defer func() { tab.Release(err) }()
+
+	if len(patterns) == 0 {
+		err = chromedp.Run(tab.Context(),
+			chromedp.Navigate(url),
+			chromedp.WaitVisible("body", chromedp.ByQuery),
+			chromedp.OuterHTML("html", &html),
+		)
+	} else {
+		capture := tab.Capture(siteID, patterns)
+		err = chromedp.Run(tab.Context(),
+			chromedp.Navigate(url),
+			chromedp.WaitVisible("body", chromedp.ByQuery),
+			capture.Wait(captureWait, captureQuiet),
+			chromedp.OuterHTML("html", &html),
+		)
+		responses = capture.Responses()
+	}
+	if err == nil {
+		err = blockedPage(html)
+	}
+
+	if err == nil && session != nil {
+		session.Record(fixtures.KindPage, url, []byte(html))
+		for _, response := range responses {
+			session.Record(fixtures.KindCapture, response.URL, response.Body)
+		}
+	}
+	return html, responses, err
+}
+
+// decodeCaptured feeds captured responses to a site's decoder. Responses the
+// decoder cannot read, such as unrelated calls caught by a broad pattern, are
+// skipped.
+func decodeCaptured(responses []browser.Response, decode DecodeFunc, site models.BettingSite) ([]models.Match, []models.Odds) {
+	var matches []models.Match
+	var odds []models.Odds
+	var seen map[string]bool
+	for _, response := range responses {
+		responseMatches, responseOdds, err := decode(response.Body, site, response.CapturedAt)
+		if err != nil {
+			log.Printf("%s: skipping captured response %s: %v", site.Name, response.URL, err)
+			continue
+		}
+		matches, odds, seen = appendNew(matches, odds, seen, responseMatches, responseOdds)
+	}
+	return matches, odds
+}
@@ -0,0 +1,33 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/browser"
+)
+
+func TestDecodeCaptured(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "api", "betika_matches.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	responses := []browser.Response{
+		{URL: "https://api.betika.com/v1/uo/matches?page=1", Body: body, CapturedAt: now},
+		// Broad patterns can catch unrelated calls, which are skipped
+		{URL: "https://api.betika.com/v1/uo/matches/count", Body: []byte(`[1, 2]`), CapturedAt: now},
+		// The page fetching the same list twice does not duplicate matches
+		{URL: "https://api.betika.com/v1/uo/matches?page=1", Body: body, CapturedAt: now},
+	}
+
+	matches, odds := decodeCaptured(responses, decodeBetika, NewBetikaScraper(nil, "").GetSiteInfo())
+	if len(matches) != 2 || len(odds) != 2 {
+		t.Fatalf("got %d matches and %d odds, want 2 and 2", len(matches), len(odds))
+	}
+	if !odds[0].ScrapedAt.Equal(now) {
+		t.Errorf("odds scraped at %v, want the capture time %v", odds[0].ScrapedAt, now)
+	}
+}
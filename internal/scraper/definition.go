@@ -0,0 +1,289 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/ratelimit"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// SiteDefinition describes a bookmaker in YAML so that it can be scraped by
+// the generic scraper without site-specific Go code
+type SiteDefinition struct {
+	ID         string               `yaml:"id"`
+	Name       string               `yaml:"name"`
+	URL        string               `yaml:"url"`
+	Sport      string               `yaml:"sport"`
+	Timezone   string               `yaml:"timezone"`
+	EntryURLs  []string             `yaml:"entry_urls"`
+	Wait       WaitDefinition       `yaml:"wait"`
+	Pagination PaginationDefinition `yaml:"pagination"`
+	Events     EventDefinition      `yaml:"events"`
+	Expect     Expectations         `yaml:"expect"`
+	RateLimit  ratelimit.Policy     `yaml:"rate_limit"`
+
+	// File is the definition's source path
+	File string `yaml:"-"`
+}
+
+// WaitDefinition says when a loaded page is ready to be read
+type WaitDefinition struct {
+	// Visible is a selector that must be visible, defaulting to the event row
+	Visible string `yaml:"visible"`
+	// Delay is extra settle time after the selector appears
+	Delay time.Duration `yaml:"delay"`
+}
+
+// PaginationDefinition says how to reach further pages of events, either by
+// clicking a "next" control or by filling {page} into a URL template
+type PaginationDefinition struct {
+	Next     string `yaml:"next"`
+	PageURL  string `yaml:"page_url"`
+	MaxPages int    `yaml:"max_pages"`
+}
+
+// EventDefinition locates the event rows and the fields within each row
+type EventDefinition struct {
+	Row     string             `yaml:"row"`
+	ID      Field              `yaml:"id"`
+	Home    Field              `yaml:"home"`
+	Away    Field              `yaml:"away"`
+	League  Field              `yaml:"league"`
+	Kickoff Field              `yaml:"kickoff"`
+	Markets []MarketDefinition `yaml:"markets"`
+}
+
+// MarketDefinition maps one price cell onto a market selection
+type MarketDefinition struct {
+	Market    string `yaml:"market"`
+	Line      string `yaml:"line"`
+	Period    string `yaml:"period"`
+	Selection string `yaml:"selection"`
+	Price     Field  `yaml:"price"`
+}
+
+// Field extracts a value from within an event row. It can be written as a
+// bare selector or as a mapping with an attribute, a pattern whose first
+// group is kept, and for kickoffs a time layout.
+type Field struct {
+	Selector string `yaml:"selector"`
+	Attr     string `yaml:"attr"`
+	Pattern  string `yaml:"pattern"`
+	Layout   string `yaml:"layout"`
+
+	pattern *regexp.Regexp
+}
+
+// UnmarshalYAML accepts either a selector string or a full field mapping
+func (f *Field) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		f.Selector = node.Value
+		return nil
+	}
+	type plain Field
+	return node.Decode((*plain)(f))
+}
+
+// IsSet reports whether the field was configured
+func (f Field) IsSet() bool {
+	return f.Selector != "" || f.Attr != ""
+}
+
+// Extract returns the field's trimmed value within a row. An empty selector
+// reads the row itself.
+func (f Field) Extract(row *goquery.Selection) string {
+	target := row
+	if f.Selector != "" {
+		target = row.Find(f.Selector).First()
+	}
+
+	var value string
+	if f.Attr != "" {
+		value, _ = target.Attr(f.Attr)
+	} else {
+		value = target.Text()
+	}
+	value = strings.Join(strings.Fields(value), " ")
+
+	if f.pattern != nil {
+		match := f.pattern.FindStringSubmatch(value)
+		switch {
+		case len(match) > 1:
+			value = match[1]
+		case len(match) == 1:
+			value = match[0]
+		default:
+			value = ""
+		}
+	}
+	return value
+}
+
+func (f *Field) compile() error {
+	if f.Pattern == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(f.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", f.Pattern, err)
+	}
+	f.pattern = pattern
+	return nil
+}
+
+// LoadSiteDefinitions reads every .yaml and .yml file in dir. Valid
+// definitions are returned even when others fail, together with the joined
+// errors of the files that were skipped. A missing directory is not an error.
+func LoadSiteDefinitions(dir string) ([]SiteDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site definitions: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var definitions []SiteDefinition
+	var errs []error
+	seen := make(map[string]string)
+	for _, name := range names {
+		def, err := LoadSiteDefinition(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, exists := seen[def.ID]; exists {
+			errs = append(errs, fmt.Errorf("%s: site id %q already defined in %s", name, def.ID, other))
+			continue
+		}
+		seen[def.ID] = name
+		definitions = append(definitions, def)
+	}
+	return definitions, errors.Join(errs...)
+}
+
+// LoadSiteDefinition reads and validates one site definition file
+func LoadSiteDefinition(path string) (SiteDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SiteDefinition{}, err
+	}
+
+	var def SiteDefinition
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		return SiteDefinition{}, fmt.Errorf("%s: %w", path, err)
+	}
+	def.File = path
+
+	if err := def.prepare(); err != nil {
+		return SiteDefinition{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, nil
+}
+
+// Rebase returns a copy of the definition with its entry and page URLs moved
+// from the live site to baseURL, keeping their paths and queries
+func (d SiteDefinition) Rebase(baseURL string) SiteDefinition {
+	entries := make([]string, len(d.EntryURLs))
+	for i, entry := range d.EntryURLs {
+		entries[i] = rebaseURL(entry, baseURL)
+	}
+	d.EntryURLs = entries
+	if d.Pagination.PageURL != "" {
+		d.Pagination.PageURL = rebaseURL(d.Pagination.PageURL, baseURL)
+	}
+	return d
+}
+
+// rebaseURL replaces the scheme and host of raw with baseURL
+func rebaseURL(raw, baseURL string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	parsed.Scheme = ""
+	parsed.Host = ""
+	parsed.User = nil
+	return strings.TrimSuffix(baseURL, "/") + parsed.String()
+}
+
+// prepare fills defaults, compiles patterns and checks required fields
+func (d *SiteDefinition) prepare() error {
+	switch {
+	case d.ID == "":
+		return errors.New("id is required")
+	case d.Name == "":
+		return errors.New("name is required")
+	case len(d.EntryURLs) == 0:
+		return errors.New("at least one entry_url is required")
+	case d.Events.Row == "":
+		return errors.New("events.row is required")
+	case !d.Events.Home.IsSet() || !d.Events.Away.IsSet():
+		return errors.New("events.home and events.away are required")
+	case !d.Events.Kickoff.IsSet():
+		return errors.New("events.kickoff is required to match fixtures across sites")
+	case len(d.Events.Markets) == 0:
+		return errors.New("at least one market is required")
+	}
+
+	if d.Sport == "" {
+		d.Sport = "football"
+	}
+	if d.URL == "" {
+		d.URL = d.EntryURLs[0]
+	}
+	if d.Wait.Visible == "" {
+		d.Wait.Visible = d.Events.Row
+	}
+	if d.Pagination.MaxPages < 1 {
+		d.Pagination.MaxPages = 1
+	}
+	if d.Timezone != "" {
+		if _, err := time.LoadLocation(d.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", d.Timezone, err)
+		}
+	}
+
+	fields := []*Field{&d.Events.ID, &d.Events.Home, &d.Events.Away, &d.Events.League, &d.Events.Kickoff}
+	for i := range d.Events.Markets {
+		market := &d.Events.Markets[i]
+		if !models.ValidMarket(market.Market) {
+			return fmt.Errorf("markets[%d]: unknown market %q", i, market.Market)
+		}
+		if market.Selection == "" || !market.Price.IsSet() {
+			return fmt.Errorf("markets[%d]: selection and price are required", i)
+		}
+		if market.Period == "" {
+			market.Period = models.PeriodFullTime
+		}
+		fields = append(fields, &market.Price)
+	}
+	for _, field := range fields {
+		if err := field.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
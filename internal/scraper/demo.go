@@ -3,26 +3,40 @@ package scraper
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
-	"strings"
 	"time"
 
 	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/simulation"
 )
 
-// DemoScraper provides sample data without actually scraping websites
+// demoBooks are the books demo mode simulates, each with its own margin
+var demoBooks = []simulation.Book{
+	{ID: "betika", Name: "Betika", Margin: 1.065},
+	{ID: "sportpesa", Name: "SportPesa", Margin: 1.055},
+	{ID: "mozzartbet", Name: "Mozzartbet", Margin: 1.05},
+	{ID: "22bet", Name: "22Bet", Margin: 1.07},
+}
+
+// DemoScraper reads one book's prices from the simulated market instead of
+// scraping a website
 type DemoScraper struct {
 	siteInfo models.BettingSite
+	market   *simulation.Engine
 }
 
-func NewDemoScraper(siteID, siteName string) *DemoScraper {
+// NewDemoScraper creates a scraper for one book of the simulated market
+func NewDemoScraper(market *simulation.Engine, siteID, siteName string) *DemoScraper {
 	return &DemoScraper{
 		siteInfo: models.BettingSite{
 			ID:     siteID,
 			Name:   siteName,
 			URL:    fmt.Sprintf("https://www.%s.com", siteID),
 			Active: true,
+			Tax:    models.KenyaTaxProfile,
 		},
+		market: market,
 	}
 }
 
@@ -31,98 +45,90 @@ func (d *DemoScraper) GetSiteInfo() models.BettingSite {
 }
 
 func (d *DemoScraper) ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error) {
-	var matches []models.Match
-	var odds []models.Odds
-
 	// Simulate some processing time
-	time.Sleep(time.Duration(rand.Intn(2000)+500) * time.Millisecond)
-
-	// Sample matches with realistic team names
-	sampleMatches := []struct {
-		home, away string
-		league     string
-	}{
-		{"Arsenal", "Chelsea", "Premier League"},
-		{"Manchester United", "Liverpool", "Premier League"},
-		{"Barcelona", "Real Madrid", "La Liga"},
-		{"Bayern Munich", "Borussia Dortmund", "Bundesliga"},
-		{"PSG", "Marseille", "Ligue 1"},
-		{"Juventus", "AC Milan", "Serie A"},
-		{"Tottenham", "Manchester City", "Premier League"},
-		{"Atletico Madrid", "Sevilla", "La Liga"},
-		{"Inter Milan", "Napoli", "Serie A"},
-		{"Leicester City", "West Ham", "Premier League"},
-		{"Valencia", "Villarreal", "La Liga"},
-		{"RB Leipzig", "Bayer Leverkusen", "Bundesliga"},
+	if err := pause(ctx, time.Duration(rand.Intn(2000)+500)*time.Millisecond); err != nil {
+		return nil, nil, err
 	}
 
-	// Generate random matches (3-8 matches per site)
-	numMatches := rand.Intn(6) + 3
-	selectedMatches := make([]int, numMatches)
-	
-	// Select random matches without duplicates
-	used := make(map[int]bool)
-	for i := 0; i < numMatches; i++ {
-		for {
-			idx := rand.Intn(len(sampleMatches))
-			if !used[idx] {
-				selectedMatches[i] = idx
-				used[idx] = true
-				break
-			}
-		}
-	}
+	matches, odds := d.quotes(nil)
+	return matches, odds, nil
+}
 
-	for i, matchIdx := range selectedMatches {
-		sample := sampleMatches[matchIdx]
-		
-		matchID := fmt.Sprintf("%s_%s_vs_%s_%d", 
-			d.siteInfo.ID,
-			strings.ReplaceAll(strings.ToLower(sample.home), " ", "_"),
-			strings.ReplaceAll(strings.ToLower(sample.away), " ", "_"),
-			time.Now().Unix()+int64(i))
-
-		match := models.Match{
-			ID:        matchID,
-			HomeTeam:  sample.home,
-			AwayTeam:  sample.away,
-			Sport:     "football",
-			League:    sample.league,
-			MatchTime: time.Now().Add(time.Duration(24+i*6) * time.Hour),
-			Status:    "upcoming",
-		}
+// ScrapeTargets refreshes only the requested fixtures and leagues
+func (d *DemoScraper) ScrapeTargets(ctx context.Context, target Target) ([]models.Match, []models.Odds, error) {
+	// A targeted refresh touches one page, so it is quicker than a full scrape
+	if err := pause(ctx, time.Duration(rand.Intn(500)+200)*time.Millisecond); err != nil {
+		return nil, nil, err
+	}
 
-		matches = append(matches, match)
+	matches, odds := d.quotes(target.Includes)
+	return matches, odds, nil
+}
 
-		// Generate realistic odds with some variation per site
-		baseHomeOdds := 1.5 + rand.Float64()*2.0  // 1.5 to 3.5
-		baseDraw := 3.0 + rand.Float64()*1.0      // 3.0 to 4.0
-		baseAwayOdds := 1.5 + rand.Float64()*2.0  // 1.5 to 3.5
+// pause waits for d, returning early with ctx's error when it is done
+func pause(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-		// Add site-specific variation
-		siteVariation := rand.Float64()*0.2 - 0.1 // -0.1 to +0.1
-		
-		homeOdds := baseHomeOdds + siteVariation
-		drawOdds := baseDraw + siteVariation
-		awayOdds := baseAwayOdds + siteVariation
+// quotes converts the book's current prices, narrowed to the fixtures
+// include accepts unless it is nil
+func (d *DemoScraper) quotes(include func(matchID, league string) bool) ([]models.Match, []models.Odds) {
+	now := time.Now()
+	var matches []models.Match
+	var odds []models.Odds
+	for _, quote := range d.market.Quotes(d.siteInfo.ID, now) {
+		matchID := d.siteInfo.ID + "_" + quote.Fixture.ID
+		if include != nil && !include(matchID, quote.Fixture.League) {
+			continue
+		}
 
-		// Ensure minimum odds
-		if homeOdds < 1.1 { homeOdds = 1.1 }
-		if drawOdds < 2.5 { drawOdds = 2.5 }
-		if awayOdds < 1.1 { awayOdds = 1.1 }
+		matches = append(matches, models.Match{
+			ID:         matchID,
+			HomeTeam:   quote.Fixture.Home,
+			AwayTeam:   quote.Fixture.Away,
+			Sport:      "football",
+			League:     quote.Fixture.League,
+			MatchTime:  quote.Fixture.Kickoff,
+			Status:     quote.Fixture.Status,
+			Provenance: models.ProvenanceDemo,
+		})
 
 		odd := models.Odds{
-			ID:        fmt.Sprintf("%s_odds", matchID),
-			MatchID:   matchID,
-			SiteID:    d.siteInfo.ID,
-			SiteName:  d.siteInfo.Name,
-			HomeWin:   homeOdds,
-			Draw:      drawOdds,
-			AwayWin:   awayOdds,
-			ScrapedAt: time.Now(),
+			ID:         matchID + "_odds",
+			MatchID:    matchID,
+			SiteID:     d.siteInfo.ID,
+			SiteName:   d.siteInfo.Name,
+			Provenance: models.ProvenanceDemo,
+			ScrapedAt:  now,
 		}
+		odd.SetMatchResult(quote.Home, quote.Draw, quote.Away)
+		addDerivedMarkets(&odd, quote.Home, quote.Draw, quote.Away)
+		odd.SetGoalMarkets(quote.Over25, quote.Under25, quote.BTTSYes, quote.BTTSNo)
 		odds = append(odds, odd)
 	}
+	return matches, odds
+}
+
+// addDerivedMarkets prices double chance and draw-no-bet from the 1X2 prices
+// with a typical bookmaker margin, so demo data covers more than one market
+func addDerivedMarkets(odd *models.Odds, home, draw, away float64) {
+	const margin = 1.05
+
+	total := 1/home + 1/draw + 1/away
+	pHome, pDraw, pAway := 1/home/total, 1/draw/total, 1/away/total
+
+	price := func(p float64) float64 {
+		return math.Round(100/(p*margin)) / 100
+	}
 
-	return matches, odds, nil
-}
\ No newline at end of file
+	odd.SetPrice(models.MarketDoubleChance, "", models.PeriodFullTime, models.SelectionHomeOrDraw, price(pHome+pDraw))
+	odd.SetPrice(models.MarketDoubleChance, "", models.PeriodFullTime, models.SelectionHomeOrAway, price(pHome+pAway))
+	odd.SetPrice(models.MarketDoubleChance, "", models.PeriodFullTime, models.SelectionDrawOrAway, price(pDraw+pAway))
+	odd.SetPrice(models.MarketDrawNoBet, "", models.PeriodFullTime, models.SelectionHome, price(pHome/(pHome+pAway)))
+	odd.SetPrice(models.MarketDrawNoBet, "", models.PeriodFullTime, models.SelectionAway, price(pAway/(pHome+pAway)))
+}
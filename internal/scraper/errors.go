@@ -0,0 +1,219 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/proxy"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Error is a scrape failure of a known kind, one of the models.Error*
+// constants
+type Error struct {
+	Kind string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// classified marks err as a failure of the given kind
+func classified(kind string, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+var (
+	// errCaptcha is returned when a site answers with a challenge page
+	// instead of odds
+	errCaptcha = classified(models.ErrorCaptcha, errors.New("site served a captcha"))
+	// errBlocked is returned when a site answers with an access denied page
+	errBlocked = classified(models.ErrorBlocked, errors.New("site denied access"))
+	// errNoEvents is returned when a site answers but lists no events
+	errNoEvents = classified(models.ErrorEmptyPage, errors.New("no events returned"))
+)
+
+// chromeErrors are the kinds of Chrome network errors
+var chromeErrors = map[string]string{
+	"net::ERR_TIMED_OUT":               models.ErrorTimeout,
+	"net::ERR_CONNECTION_TIMED_OUT":    models.ErrorTimeout,
+	"net::ERR_CONNECTION_RESET":        models.ErrorUnavailable,
+	"net::ERR_CONNECTION_CLOSED":       models.ErrorUnavailable,
+	"net::ERR_CONNECTION_REFUSED":      models.ErrorUnavailable,
+	"net::ERR_EMPTY_RESPONSE":          models.ErrorUnavailable,
+	"net::ERR_NETWORK_CHANGED":         models.ErrorUnavailable,
+	"net::ERR_NAME_NOT_RESOLVED":       models.ErrorUnavailable,
+	"net::ERR_PROXY_CONNECTION_FAILED": models.ErrorUnavailable,
+}
+
+// Kind classifies a scrape error: errors marked with a kind keep it, HTTP
+// statuses, timeouts and dropped connections are recognised, and anything
+// else is unknown. Where errors were joined, such as an API failure and its
+// browser fallback's, the first with a known kind decides. A nil error has no
+// kind.
+func Kind(err error) string {
+	if err == nil {
+		return ""
+	}
+	if kind := wrappedKind(err); kind != "" {
+		return kind
+	}
+
+	message := err.Error()
+	for code, kind := range chromeErrors {
+		if strings.Contains(message, code) {
+			return kind
+		}
+	}
+	return models.ErrorUnknown
+}
+
+// wrappedKind returns the kind of the outermost recognised error in err's
+// tree, or "" when none is recognised
+func wrappedKind(err error) string {
+	if kind := ownKind(err); kind != "" {
+		return kind
+	}
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := wrapper.Unwrap(); inner != nil {
+			return wrappedKind(inner)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapper.Unwrap() {
+			if kind := wrappedKind(inner); kind != "" {
+				return kind
+			}
+		}
+	}
+	return ""
+}
+
+// hasKind reports whether any error in err's tree, not just the one Kind
+// picks, is of the given kind
+func hasKind(err error, kind string) bool {
+	if err == nil {
+		return false
+	}
+	if ownKind(err) == kind {
+		return true
+	}
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		return hasKind(wrapper.Unwrap(), kind)
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapper.Unwrap() {
+			if hasKind(inner, kind) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ownKind returns the kind err itself is recognised as, without looking at
+// the errors it wraps
+func ownKind(err error) string {
+	switch e := err.(type) {
+	case *Error:
+		return e.Kind
+	case *statusError:
+		return statusKind(e.Code)
+	case net.Error:
+		if e.Timeout() {
+			return models.ErrorTimeout
+		}
+	}
+	switch {
+	case err == ErrCircuitOpen:
+		return models.ErrorCircuitOpen
+	case err == context.DeadlineExceeded:
+		return models.ErrorTimeout
+	case err == proxy.ErrNoProxy,
+		err == syscall.ECONNRESET,
+		err == syscall.ECONNREFUSED,
+		err == io.ErrUnexpectedEOF:
+		return models.ErrorUnavailable
+	}
+	return ""
+}
+
+// statusKind classifies an unsuccessful HTTP status
+func statusKind(code int) string {
+	switch {
+	case code == http.StatusForbidden:
+		return models.ErrorBlocked
+	case code == http.StatusTooManyRequests:
+		return models.ErrorRateLimited
+	case code == http.StatusNotFound || code == http.StatusGone:
+		// The page or endpoint the scraper knows has moved
+		return models.ErrorLayoutChanged
+	case code >= http.StatusInternalServerError:
+		return models.ErrorUnavailable
+	}
+	return models.ErrorUnknown
+}
+
+// retryable reports whether a failure of a kind is likely to go away on its
+// own: timeouts, sites that are down and rate limits, which the rate limiter
+// waits out before the retry's requests. Layout changes, empty pages and
+// parse failures are not retried since the next attempt sees the same page,
+// and bans are only retried once they rotate the site's identity.
+func retryable(kind string) bool {
+	switch kind {
+	case models.ErrorTimeout, models.ErrorUnavailable, models.ErrorRateLimited:
+		return true
+	}
+	return false
+}
+
+// banSignal maps a scrape's outcome onto what it says about the identity it
+// used. A captcha or block anywhere in a joined error counts, even behind a
+// failure Kind ranks first. Failures unrelated to blocking say nothing, and
+// report false.
+func banSignal(err error) (proxy.Signal, bool) {
+	switch {
+	case err == nil:
+		return proxy.Success, true
+	case hasKind(err, models.ErrorCaptcha):
+		return proxy.Captcha, true
+	case hasKind(err, models.ErrorBlocked):
+		return proxy.Forbidden, true
+	}
+	return 0, false
+}
+
+// minPageText is the least visible text a page with content has; below it the
+// site served an empty shell, such as a page that failed to render
+const minPageText = 200
+
+// noEvents is the error for pages where no events were found: an empty page
+// when the pages have next to no text, and otherwise a layout change, since
+// the scraper's selectors no longer match what the site shows
+func noEvents(pages []string, err error) error {
+	for _, page := range pages {
+		doc, parseErr := goquery.NewDocumentFromReader(strings.NewReader(page))
+		if parseErr != nil {
+			continue
+		}
+		doc.Find("script, style, noscript").Remove()
+		if len(strings.Join(strings.Fields(doc.Find("body").Text()), " ")) >= minPageText {
+			return classified(models.ErrorLayoutChanged, err)
+		}
+	}
+	return classified(models.ErrorEmptyPage, err)
+}
@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/proxy"
+)
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{&statusError{Code: http.StatusBadGateway}, models.ErrorUnavailable},
+		{&statusError{Code: http.StatusTooManyRequests}, models.ErrorRateLimited},
+		{fmt.Errorf("api: %w", &statusError{Code: http.StatusForbidden}), models.ErrorBlocked},
+		{&statusError{Code: http.StatusNotFound}, models.ErrorLayoutChanged},
+		{fmt.Errorf("navigate: %w", context.DeadlineExceeded), models.ErrorTimeout},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), models.ErrorUnavailable},
+		{errors.New("page load error net::ERR_CONNECTION_RESET"), models.ErrorUnavailable},
+		{errors.New("page load error net::ERR_TIMED_OUT"), models.ErrorTimeout},
+		{fmt.Errorf("failed to load Betika page: %w", errCaptcha), models.ErrorCaptcha},
+		{fmt.Errorf("Betika API: %w", errNoEvents), models.ErrorEmptyPage},
+		{fmt.Errorf("%w, next probe at 12:00", ErrCircuitOpen), models.ErrorCircuitOpen},
+		{fmt.Errorf("%w for betika", proxy.ErrNoProxy), models.ErrorUnavailable},
+		{errors.New(`exec: "google-chrome": executable file not found in $PATH`), models.ErrorUnknown},
+		// The API's failure decides over its browser fallback's
+		{fmt.Errorf("api: %w; browser: %w", &statusError{Code: http.StatusTooManyRequests}, errBlocked), models.ErrorRateLimited},
+		{fmt.Errorf("api: %w; browser: %w", errors.New("bad gateway"), errBlocked), models.ErrorBlocked},
+	}
+	for _, test := range tests {
+		if got := Kind(test.err); got != test.want {
+			t.Errorf("Kind(%v) = %q, want %q", test.err, got, test.want)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, kind := range models.ErrorKinds {
+		want := kind == models.ErrorTimeout || kind == models.ErrorUnavailable || kind == models.ErrorRateLimited
+		if got := retryable(kind); got != want {
+			t.Errorf("retryable(%q) = %v, want %v", kind, got, want)
+		}
+	}
+}
+
+func TestBanSignal(t *testing.T) {
+	tests := []struct {
+		err    error
+		want   proxy.Signal
+		report bool
+	}{
+		{nil, proxy.Success, true},
+		{fmt.Errorf("failed to load Betika page: %w", errCaptcha), proxy.Captcha, true},
+		{&statusError{Code: http.StatusForbidden}, proxy.Forbidden, true},
+		{&statusError{Code: http.StatusBadGateway}, 0, false},
+		{classified(models.ErrorParse, errors.New("bad json")), 0, false},
+		// A ban behind a failure that Kind ranks first still counts
+		{fmt.Errorf("api: %w; browser: %w", &statusError{Code: http.StatusTooManyRequests}, errCaptcha), proxy.Captcha, true},
+		{fmt.Errorf("api: %w; browser: %w", &statusError{Code: http.StatusTooManyRequests}, errBlocked), proxy.Forbidden, true},
+		{fmt.Errorf("api: %w; browser: %w", errBlocked, errCaptcha), proxy.Captcha, true},
+	}
+	for _, tt := range tests {
+		if got, report := banSignal(tt.err); got != tt.want || report != tt.report {
+			t.Errorf("banSignal(%v) = %v, %v, want %v, %v", tt.err, got, report, tt.want, tt.report)
+		}
+	}
+}
+
+func TestNoEvents(t *testing.T) {
+	cause := errors.New("no events matched")
+	shell := `<html><body><div id="app"></div><script>` + strings.Repeat("x", 500) + `</script></body></html>`
+	redesigned := `<html><body><main>` + strings.Repeat("<p>Premier League Arsenal v Chelsea 2.10 3.40 3.20</p>", 10) + `</main></body></html>`
+
+	if kind := Kind(noEvents([]string{shell}, cause)); kind != models.ErrorEmptyPage {
+		t.Errorf("a page without text is %q, want empty_page", kind)
+	}
+	if kind := Kind(noEvents([]string{shell, redesigned}, cause)); kind != models.ErrorLayoutChanged {
+		t.Errorf("a page with content is %q, want layout_changed", kind)
+	}
+	if err := noEvents(nil, cause); !errors.Is(err, cause) {
+		t.Errorf("got %v, want the cause wrapped", err)
+	}
+}
@@ -0,0 +1,333 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"betting-odds-scraper/internal/browser"
+	"betting-odds-scraper/internal/fixtures"
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/ratelimit"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// kenyaLocation is the default timezone for kickoff times shown without one
+var kenyaLocation = time.FixedZone("EAT", 3*60*60)
+
+// GenericScraper scrapes a bookmaker described by a SiteDefinition
+type GenericScraper struct {
+	def      SiteDefinition
+	siteInfo models.BettingSite
+	pool     *browser.Pool
+	location *time.Location
+}
+
+func NewGenericScraper(def SiteDefinition, pool *browser.Pool) *GenericScraper {
+	location := kenyaLocation
+	if def.Timezone != "" {
+		if loc, err := time.LoadLocation(def.Timezone); err == nil {
+			location = loc
+		}
+	}
+
+	return &GenericScraper{
+		def: def,
+		siteInfo: models.BettingSite{
+			ID:     def.ID,
+			Name:   def.Name,
+			URL:    def.URL,
+			Active: true,
+			Tax:    models.KenyaTaxProfile,
+		},
+		pool:     pool,
+		location: location,
+	}
+}
+
+func (g *GenericScraper) GetSiteInfo() models.BettingSite {
+	return g.siteInfo
+}
+
+// Expectations returns what the definition says a healthy scrape looks like
+func (g *GenericScraper) Expectations() Expectations {
+	return g.def.Expect
+}
+
+// RateLimit returns the definition's request rate limit
+func (g *GenericScraper) RateLimit() ratelimit.Policy {
+	return g.def.RateLimit
+}
+
+func (g *GenericScraper) ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error) {
+	pages, err := g.fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matches []models.Match
+	var odds []models.Odds
+	var seen map[string]bool
+	rows := 0
+	for _, page := range pages {
+		pageMatches, pageOdds, pageRows, err := g.Parse(page, fixtures.Now(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		rows += pageRows
+		matches, odds, seen = appendNew(matches, odds, seen, pageMatches, pageOdds)
+	}
+
+	if rows == 0 {
+		return nil, nil, noEvents(pages, fmt.Errorf("no events matched %q on %s", g.def.Events.Row, g.def.Name))
+	}
+	matches, odds = stamp(matches, odds, models.ProvenanceLive)
+	return matches, odds, nil
+}
+
+// ScrapeTargets scrapes the site's pages in full and keeps only the targeted
+// fixtures and leagues
+func (g *GenericScraper) ScrapeTargets(ctx context.Context, target Target) ([]models.Match, []models.Odds, error) {
+	matches, odds, err := g.ScrapeOdds(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches, odds = target.narrow(matches, odds)
+	return matches, odds, nil
+}
+
+// fetch loads every entry URL and its further pages in one browser tab,
+// returning the HTML of each page. Record and replay sessions record or stand
+// in for the pages.
+func (g *GenericScraper) fetch(ctx context.Context) (pages []string, err error) {
+	session := fixtures.FromContext(ctx)
+	if session.Replaying() {
+		for _, artifact := range session.Drain(fixtures.KindPage) {
+			pages = append(pages, string(artifact.Body))
+		}
+		if len(pages) == 0 {
+			return nil, fmt.Errorf("%w: %s for %s", fixtures.ErrExhausted, fixtures.KindPage, g.def.ID)
+		}
+		return pages, nil
+	}
+
+	tab, err := g.pool.NewTab(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open browser tab: %w", err)
+	}
+	defer func() { tab.Release(err) }()
+	chromeCtx := tab.Context()
+
+	for _, entry := range g.def.EntryURLs {
+		html, err := g.load(chromeCtx, chromedp.Navigate(entry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s page %s: %w", g.def.Name, entry, err)
+		}
+		pages = append(pages, html)
+		if session != nil {
+			session.Record(fixtures.KindPage, entry, []byte(html))
+		}
+
+		for page := 2; page <= g.def.Pagination.MaxPages; page++ {
+			action, ok := g.nextPage(chromeCtx, page)
+			if !ok {
+				break
+			}
+			html, err := g.load(chromeCtx, action)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s page %d: %w", g.def.Name, page, err)
+			}
+			pages = append(pages, html)
+			if session != nil {
+				session.Record(fixtures.KindPage, fmt.Sprintf("%s#page=%d", entry, page), []byte(html))
+			}
+		}
+	}
+	return pages, nil
+}
+
+// nextPage returns the action that opens the given page, or false when the
+// site has no further pages
+func (g *GenericScraper) nextPage(ctx context.Context, page int) (chromedp.Action, bool) {
+	pagination := g.def.Pagination
+	switch {
+	case pagination.PageURL != "":
+		return chromedp.Navigate(strings.ReplaceAll(pagination.PageURL, "{page}", strconv.Itoa(page))), true
+	case pagination.Next != "":
+		// Stop when there is no next control left to click
+		var nodes []*cdp.Node
+		if err := chromedp.Run(ctx, chromedp.Nodes(pagination.Next, &nodes, chromedp.ByQuery, chromedp.AtLeast(0))); err != nil || len(nodes) == 0 {
+			return nil, false
+		}
+		return chromedp.Click(pagination.Next, chromedp.ByQuery), true
+	}
+	return nil, false
+}
+
+// load runs a navigation action, waits for the page to be ready and returns
+// its HTML. A captcha or access denied page is recognised as soon as it has
+// loaded, rather than left to time out waiting for odds that never appear.
+func (g *GenericScraper) load(ctx context.Context, navigate chromedp.Action) (string, error) {
+	var html string
+	if err := chromedp.Run(ctx, navigate, chromedp.WaitReady("body", chromedp.ByQuery), chromedp.OuterHTML("html", &html)); err != nil {
+		return "", err
+	}
+	if err := blockedPage(html); err != nil {
+		return "", err
+	}
+
+	actions := []chromedp.Action{chromedp.WaitVisible(g.def.Wait.Visible, chromedp.ByQuery)}
+	if g.def.Wait.Delay > 0 {
+		actions = append(actions, chromedp.Sleep(g.def.Wait.Delay))
+	}
+	actions = append(actions, chromedp.OuterHTML("html", &html))
+
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return "", err
+	}
+	return html, blockedPage(html)
+}
+
+// Parse extracts matches and odds from one page of HTML. It also returns the
+// number of event rows found, including rows that could not be read.
+func (g *GenericScraper) Parse(html string, now time.Time) ([]models.Match, []models.Odds, int, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, nil, 0, classified(models.ErrorParse, fmt.Errorf("failed to parse HTML: %w", err))
+	}
+
+	var matches []models.Match
+	var odds []models.Odds
+	events := g.def.Events
+	rows := doc.Find(events.Row)
+
+	rows.Each(func(_ int, row *goquery.Selection) {
+		home := events.Home.Extract(row)
+		away := events.Away.Extract(row)
+		kickoff, ok := g.parseKickoff(events.Kickoff.Extract(row), now)
+		if home == "" || away == "" || !ok {
+			return
+		}
+
+		matchID := fmt.Sprintf("%s_%s_vs_%s_%d", g.def.ID, normalizeName(home), normalizeName(away), kickoff.Unix())
+		if events.ID.IsSet() {
+			if id := events.ID.Extract(row); id != "" {
+				matchID = g.def.ID + "_" + id
+			}
+		}
+
+		odd := models.Odds{
+			ID:        matchID + "_odds",
+			MatchID:   matchID,
+			SiteID:    g.def.ID,
+			SiteName:  g.def.Name,
+			ScrapedAt: now,
+		}
+		for _, market := range events.Markets {
+			if value, ok := parsePrice(market.Price.Extract(row)); ok {
+				odd.SetPrice(market.Market, market.Line, market.Period, market.Selection, value)
+			}
+		}
+		if len(odd.Markets) == 0 {
+			return
+		}
+
+		matches = append(matches, models.Match{
+			ID:        matchID,
+			HomeTeam:  home,
+			AwayTeam:  away,
+			Sport:     g.def.Sport,
+			League:    events.League.Extract(row),
+			MatchTime: kickoff,
+			Status:    "upcoming",
+		})
+		odds = append(odds, odd)
+	})
+
+	return matches, odds, rows.Length(), nil
+}
+
+// parseKickoff reads a kickoff time with the field's layout in the site's
+// timezone. "unix" and "unix_ms" layouts read epoch timestamps, and layouts
+// without a date are taken as the next occurrence of that time. "Today"
+// and "Tomorrow" prefixes pin the time to that day.
+func (g *GenericScraper) parseKickoff(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	layout := g.def.Events.Kickoff.Layout
+	switch layout {
+	case "unix", "unix_ms":
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if layout == "unix_ms" {
+			return time.UnixMilli(epoch).In(g.location), true
+		}
+		return time.Unix(epoch, 0).In(g.location), true
+	case "":
+		layout = time.RFC3339
+	}
+
+	// days is how many days after today a prefix places the kickoff, or -1
+	days := -1
+	lower := strings.ToLower(value)
+	for offset, prefix := range []string{"today", "tomorrow"} {
+		if strings.HasPrefix(lower, prefix) {
+			value = strings.TrimSpace(value[len(prefix):])
+			days = offset
+		}
+	}
+
+	kickoff, err := time.ParseInLocation(layout, value, g.location)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	hasDate, hasYear := layoutParts(layout)
+	local := now.In(g.location)
+	switch {
+	case !hasDate:
+		kickoff = time.Date(local.Year(), local.Month(), local.Day(), kickoff.Hour(), kickoff.Minute(), 0, 0, g.location)
+		if days >= 0 {
+			kickoff = kickoff.AddDate(0, 0, days)
+		} else if kickoff.Before(local.Add(-3 * time.Hour)) {
+			// A bare time is the next occurrence of that time
+			kickoff = kickoff.AddDate(0, 0, 1)
+		}
+	case !hasYear:
+		// A day and month without a year falls within the coming months
+		kickoff = time.Date(local.Year(), kickoff.Month(), kickoff.Day(), kickoff.Hour(), kickoff.Minute(), 0, 0, g.location)
+		if kickoff.Before(local.AddDate(0, -6, 0)) {
+			kickoff = kickoff.AddDate(1, 0, 0)
+		}
+	}
+	return kickoff, true
+}
+
+// layoutParts reports whether a time layout includes a date and a year, by
+// round-tripping a probe time through it
+func layoutParts(layout string) (hasDate, hasYear bool) {
+	probe := time.Date(2031, time.March, 15, 10, 30, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, probe.Format(layout))
+	if err != nil {
+		return false, false
+	}
+	return parsed.Month() == probe.Month() && parsed.Day() == probe.Day(), parsed.Year() == probe.Year()
+}
+
+// parsePrice reads a decimal price, accepting a comma as decimal separator
+func parsePrice(value string) (float64, bool) {
+	price, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+	if err != nil || price <= 1 {
+		return 0, false
+	}
+	return price, true
+}
@@ -0,0 +1,41 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseKickoff(t *testing.T) {
+	def := SiteDefinition{ID: "test", Events: EventDefinition{Kickoff: Field{Layout: "15:04"}}}
+	scraper := NewGenericScraper(def, nil)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, kenyaLocation)
+	}
+
+	tests := []struct {
+		value string
+		now   time.Time
+		want  time.Time
+	}{
+		{"10:00", at(17, 9, 0), at(17, 10, 0)},
+		// A bare time long gone is tomorrow's
+		{"08:00", at(17, 22, 0), at(18, 8, 0)},
+		// A bare time just gone is a match in play
+		{"20:00", at(17, 22, 0), at(17, 20, 0)},
+		{"Tomorrow 10:00", at(17, 9, 0), at(18, 10, 0)},
+		{"tomorrow 08:00", at(17, 22, 0), at(18, 8, 0)},
+		{"Today 10:00", at(17, 9, 0), at(17, 10, 0)},
+		// Today stays today however long ago the time was
+		{"Today 08:00", at(17, 22, 0), at(17, 8, 0)},
+	}
+	for _, tt := range tests {
+		got, ok := scraper.parseKickoff(tt.value, tt.now)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("parseKickoff(%q) at %v = %v, %v, want %v", tt.value, tt.now, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := scraper.parseKickoff("Tomorrow", at(17, 9, 0)); ok {
+		t.Error("parsed a prefix without a time")
+	}
+}
@@ -0,0 +1,188 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/normalize"
+)
+
+// healthWeight is how much the latest scrape moves a site's health score,
+// which is a moving average of 100 for ok, 50 for degraded and 0 for failed
+const healthWeight = 0.3
+
+// alertHistory is the number of recent alerts kept for the API
+const alertHistory = 50
+
+// alertTimeout bounds a webhook delivery
+const alertTimeout = 10 * time.Second
+
+// Health tracks what healthy scrapes look like and raises alerts when a
+// site's scrapes stop being healthy
+type Health struct {
+	webhook    string
+	client     *http.Client
+	normalizer *normalize.Normalizer
+
+	mutex   sync.RWMutex
+	leagues map[string]bool
+	alerts  []models.Alert
+}
+
+// NewHealth creates a health tracker that posts alerts to webhook, if set
+func NewHealth(webhook string, normalizer *normalize.Normalizer) *Health {
+	return &Health{
+		webhook:    webhook,
+		client:     &http.Client{Timeout: alertTimeout},
+		normalizer: normalizer,
+		leagues:    make(map[string]bool),
+	}
+}
+
+// KnownLeague reports whether a normalized league name is curated in the
+// alias file, listed in the site's expectations or was seen in a healthy
+// scrape of any site
+func (h *Health) KnownLeague(league string, expect Expectations) bool {
+	folded := normalize.Fold(league)
+	for _, l := range expect.Leagues {
+		if normalize.Fold(l) == folded {
+			return true
+		}
+	}
+	if h.normalizer != nil && h.normalizer.Curated(normalize.KindLeague, league) {
+		return true
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.leagues[folded]
+}
+
+// Learn remembers the leagues of a healthy scrape
+func (h *Health) Learn(matches []models.Match) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, match := range matches {
+		if folded := normalize.Fold(match.League); folded != "" {
+			h.leagues[folded] = true
+		}
+	}
+}
+
+// Baseline is the average event count of a site's recent ok full scrapes
+func Baseline(history []models.ScrapeResult) float64 {
+	total, count := 0, 0
+	for _, result := range history {
+		if result.State() == models.ScrapeOK && result.Checks != nil && !result.Checks.Partial {
+			total += result.MatchCount
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// Score updates a site's health score with the status of its latest scrape
+func Score(history []models.ScrapeResult, status string) float64 {
+	score := 0.0
+	switch status {
+	case models.ScrapeOK:
+		score = 100
+	case models.ScrapeDegraded:
+		score = 50
+	}
+	if len(history) == 0 {
+		return score
+	}
+
+	previous := history[len(history)-1]
+	if previous.Status == "" {
+		// Results stored before health scores count at face value
+		previous.Health = Score(nil, previous.State())
+	}
+	return round(previous.Health*(1-healthWeight)+score*healthWeight, 1)
+}
+
+// Observe raises an alert when a site moves away from ok, or back to it
+func (h *Health) Observe(previous *models.ScrapeResult, result models.ScrapeResult) {
+	previousStatus := models.ScrapeOK
+	if previous != nil {
+		previousStatus = previous.State()
+	}
+	status := result.State()
+	if status == previousStatus {
+		return
+	}
+
+	alert := models.Alert{
+		SiteID:   result.SiteID,
+		Status:   status,
+		Issues:   result.Issues,
+		RaisedAt: result.ScrapedAt,
+	}
+	switch status {
+	case models.ScrapeOK:
+		alert.Message = fmt.Sprintf("%s recovered: %d events, health %.0f", result.SiteID, result.MatchCount, result.Health)
+	case models.ScrapeDegraded:
+		alert.Message = fmt.Sprintf("%s is degraded: %s", result.SiteID, strings.Join(result.Issues, "; "))
+	default:
+		alert.Message = fmt.Sprintf("%s is failing: %s", result.SiteID, result.Error)
+	}
+	log.Printf("ALERT: %s", alert.Message)
+
+	h.mutex.Lock()
+	h.alerts = append(h.alerts, alert)
+	if len(h.alerts) > alertHistory {
+		h.alerts = h.alerts[len(h.alerts)-alertHistory:]
+	}
+	h.mutex.Unlock()
+
+	if h.webhook != "" {
+		go h.post(alert)
+	}
+}
+
+// post delivers an alert to the webhook. The text field makes the payload
+// readable by Slack-compatible incoming webhooks.
+func (h *Health) post(alert models.Alert) {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+		models.Alert
+	}{Text: alert.Message, Alert: alert})
+	if err != nil {
+		log.Printf("Failed to encode alert: %v", err)
+		return
+	}
+
+	resp, err := h.client.Post(h.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Failed to send alert for %s: %v", alert.SiteID, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Alert webhook for %s returned %s", alert.SiteID, resp.Status)
+	}
+}
+
+// Alerts returns the recent alerts, newest first
+func (h *Health) Alerts() []models.Alert {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	alerts := make([]models.Alert, len(h.alerts))
+	for i, alert := range h.alerts {
+		alerts[len(h.alerts)-1-i] = alert
+	}
+	return alerts
+}
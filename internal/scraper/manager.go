@@ -2,21 +2,51 @@ package scraper
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"betting-odds-scraper/internal/analysis"
+	"betting-odds-scraper/internal/browser"
 	"betting-odds-scraper/internal/config"
+	"betting-odds-scraper/internal/fixtures"
 	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/normalize"
+	"betting-odds-scraper/internal/proxy"
+	"betting-odds-scraper/internal/ratelimit"
+	"betting-odds-scraper/internal/simulation"
+	"betting-odds-scraper/internal/store"
 )
 
+// resultHistory is the number of recent scrape results returned per site
+const resultHistory = 10
+
 type Manager struct {
-	config   *config.Config
-	scrapers map[string]Scraper
-	results  map[string][]models.ScrapeResult
-	odds     map[string][]models.Odds
-	matches  map[string]models.Match
-	mutex    sync.RWMutex
+	config       *config.Config
+	scrapers     map[string]Scraper
+	sites        map[string]models.BettingSite
+	taxOverrides map[string]models.TaxProfile
+	store        store.Store
+	// odds and results mirror the store's current odds and recent scrape
+	// results, which every scrape and best odds request reads
+	odds         map[string][]models.Odds
+	results      map[string][]models.ScrapeResult
+	arbitrage    []models.Arbitrage
+	netArbitrage []models.Arbitrage
+	resolver     *MatchResolver
+	normalizer   *normalize.Normalizer
+	health       *Health
+	retry        RetryPolicy
+	breakers     map[string]*Breaker
+	metrics      *scrapeMetrics
+	limiter      *ratelimit.Limiter
+	proxies      *proxy.Pool
+	browsers     *browser.Pool
+	mutex        sync.RWMutex
 }
 
 type Scraper interface {
@@ -24,28 +54,153 @@ type Scraper interface {
 	ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error)
 }
 
+// PacedScraper is a scraper with its own request rate limit
+type PacedScraper interface {
+	Scraper
+	RateLimit() ratelimit.Policy
+}
+
+// TargetedScraper is a scraper that can refresh selected fixtures or leagues
+// instead of its whole football page
+type TargetedScraper interface {
+	Scraper
+	ScrapeTargets(ctx context.Context, target Target) ([]models.Match, []models.Odds, error)
+}
+
+// Target narrows a scrape to fixtures, by the site's own match ID, or leagues
+type Target struct {
+	MatchIDs []string `json:"match_ids,omitempty"`
+	Leagues  []string `json:"leagues,omitempty"`
+}
+
+// Includes reports whether a site match or league is part of the target
+func (t Target) Includes(matchID, league string) bool {
+	for _, id := range t.MatchIDs {
+		if id == matchID {
+			return true
+		}
+	}
+	for _, l := range t.Leagues {
+		if strings.EqualFold(l, league) {
+			return true
+		}
+	}
+	return false
+}
+
+// narrow keeps the matches the target includes and their odds, for scrapers
+// that can only fetch a site in full
+func (t Target) narrow(matches []models.Match, odds []models.Odds) ([]models.Match, []models.Odds) {
+	kept := make(map[string]bool)
+	var narrowed []models.Match
+	for _, match := range matches {
+		if t.Includes(match.ID, match.League) {
+			kept[match.ID] = true
+			narrowed = append(narrowed, match)
+		}
+	}
+
+	var narrowedOdds []models.Odds
+	for _, odd := range odds {
+		if kept[odd.MatchID] {
+			narrowedOdds = append(narrowedOdds, odd)
+		}
+	}
+	return narrowed, narrowedOdds
+}
+
 func NewManager(cfg *config.Config) *Manager {
+	normalizer, err := normalize.New(cfg)
+	if err != nil {
+		log.Printf("Failed to load name normalization data: %v", err)
+	}
+
+	taxOverrides, err := analysis.LoadTaxProfiles(cfg.TaxProfilesFile)
+	if err != nil {
+		log.Printf("Failed to load tax profiles, using site defaults: %v", err)
+		taxOverrides = make(map[string]models.TaxProfile)
+	}
+
+	proxies, err := proxy.New(cfg)
+	if err != nil {
+		log.Printf("Failed to load proxies from %s: %v", cfg.ProxyFile, err)
+	}
+	proxies.Start()
+
+	st, err := store.New(cfg)
+	if err != nil {
+		log.Printf("Failed to open %s store, falling back to memory: %v", cfg.StoreDriver, err)
+		st = store.NewMemoryStore()
+	}
+
 	manager := &Manager{
-		config:   cfg,
-		scrapers: make(map[string]Scraper),
-		results:  make(map[string][]models.ScrapeResult),
-		odds:     make(map[string][]models.Odds),
-		matches:  make(map[string]models.Match),
-	}
-
-	// Check if we should use demo mode (faster, no Chrome required)
-	if cfg.LogLevel == "demo" {
-		// Register demo scrapers for testing
-		manager.RegisterScraper(NewDemoScraper("betika", "Betika"))
-		manager.RegisterScraper(NewDemoScraper("sportpesa", "SportPesa"))
-		manager.RegisterScraper(NewDemoScraper("betway", "Betway"))
-		manager.RegisterScraper(NewDemoScraper("odibets", "Odibets"))
+		config:       cfg,
+		scrapers:     make(map[string]Scraper),
+		sites:        make(map[string]models.BettingSite),
+		taxOverrides: taxOverrides,
+		store:        st,
+		resolver:     NewMatchResolver(cfg.MatchKickoffWindow),
+		normalizer:   normalizer,
+		health:       NewHealth(cfg.AlertWebhookURL, normalizer),
+		retry:        NewRetryPolicy(cfg),
+		breakers:     make(map[string]*Breaker),
+		metrics:      newScrapeMetrics(),
+		limiter:      ratelimit.New(cfg),
+		proxies:      proxies,
+	}
+
+	manager.odds, err = st.CurrentOdds()
+	if err != nil || manager.odds == nil {
+		log.Printf("Failed to load stored odds: %v", err)
+		manager.odds = make(map[string][]models.Odds)
+	}
+	manager.results, err = st.ScrapeResults(resultHistory)
+	if err != nil || manager.results == nil {
+		log.Printf("Failed to load stored scrape results: %v", err)
+		manager.results = make(map[string][]models.ScrapeResult)
+	}
+
+	// Restore canonical fixtures so stored odds keep lining up after a restart
+	matches, err := st.Matches()
+	if err != nil {
+		log.Printf("Failed to load stored matches: %v", err)
+	}
+	for _, match := range matches {
+		manager.resolver.Seed(match)
+	}
+
+	// Demo mode prices every book from one simulated market, without Chrome
+	if cfg.Mode == config.ModeDemo {
+		market := simulation.New(cfg.DemoSeed, demoBooks, time.Now())
+		for _, book := range demoBooks {
+			manager.RegisterScraper(NewDemoScraper(market, book.ID, book.Name))
+		}
 	} else {
-		// Register real Kenyan betting site scrapers
-		manager.RegisterScraper(NewBetikaScraper())
-		manager.RegisterScraper(NewSportPesaScraper())
-		manager.RegisterScraper(NewBetwayScraper())
-		manager.RegisterScraper(NewOdibetsScraper())
+		// Register real Kenyan betting site scrapers, sharing one browser pool
+		manager.browsers = browser.NewPool(cfg)
+		manager.RegisterScraper(manager.withAPI(NewBetikaScraper(manager.browsers, manager.baseURL("betika")), BetikaAPI(manager.baseURL("betika"))))
+		manager.RegisterScraper(manager.withAPI(NewSportPesaScraper(manager.browsers, manager.baseURL("sportpesa")), SportPesaAPI(manager.baseURL("sportpesa"))))
+
+		// Sites described in YAML need no Go code, and replace a built-in
+		// scraper with the same ID
+		definitions, err := LoadSiteDefinitions(cfg.SitesDir)
+		if err != nil {
+			log.Printf("Skipped invalid site definitions: %v", err)
+		}
+		for _, def := range definitions {
+			if _, exists := manager.scrapers[def.ID]; exists {
+				log.Printf("Site definition %s replaces the built-in %s scraper", def.File, def.ID)
+			}
+			if base := manager.baseURL(def.ID); base != "" {
+				def = def.Rebase(base)
+			}
+			manager.RegisterScraper(NewGenericScraper(def, manager.browsers))
+		}
+	}
+
+	if len(matches) > 0 {
+		log.Printf("Loaded %d stored matches", len(matches))
+		manager.refreshArbitrage()
 	}
 
 	return manager
@@ -54,28 +209,105 @@ func NewManager(cfg *config.Config) *Manager {
 func (m *Manager) RegisterScraper(scraper Scraper) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	
+
 	siteInfo := scraper.GetSiteInfo()
+	if tax, exists := m.taxOverrides[siteInfo.ID]; exists {
+		siteInfo.Tax = tax
+	}
 	m.scrapers[siteInfo.ID] = scraper
+	m.sites[siteInfo.ID] = siteInfo
+	if _, exists := m.breakers[siteInfo.ID]; !exists {
+		m.breakers[siteInfo.ID] = NewBreaker(m.config.BreakerThreshold, m.config.BreakerCooldown)
+	}
+	m.limiter.Configure(m.siteURL(siteInfo), m.ratePolicy(scraper))
 	log.Printf("Registered scraper for %s", siteInfo.Name)
 }
 
+// baseURL returns the configured replacement for a site's live URLs, such as
+// the mock bookmaker, or "" for the live site. A "*" entry serves every site
+// from <url>/<site>.
+func (m *Manager) baseURL(siteID string) string {
+	if base, exists := m.config.SiteBaseURLs[siteID]; exists {
+		return strings.TrimSuffix(base, "/")
+	}
+	if base, exists := m.config.SiteBaseURLs["*"]; exists {
+		return strings.TrimSuffix(base, "/") + "/" + siteID
+	}
+	return ""
+}
+
+// siteURL is where a site's requests go: its base URL override, or its live
+// URL
+func (m *Manager) siteURL(site models.BettingSite) string {
+	if base := m.baseURL(site.ID); base != "" {
+		return base
+	}
+	return site.URL
+}
+
+// ratePolicy returns a site's request rate limit: its SITE_RATE_LIMITS
+// entry, then its own, with unset fields taking the configured defaults
+func (m *Manager) ratePolicy(scraper Scraper) ratelimit.Policy {
+	var policy ratelimit.Policy
+	if paced, ok := scraper.(PacedScraper); ok {
+		policy = paced.RateLimit()
+	}
+	if limit, exists := m.config.SiteRateLimits[scraper.GetSiteInfo().ID]; exists {
+		policy.Requests, policy.Window = limit.Requests, limit.Window
+	}
+	return policy
+}
+
+// withAPI puts a site's JSON API in front of its browser scraper, which is
+// kept as the fallback, unless site APIs are disabled
+func (m *Manager) withAPI(fallback Scraper, api SiteAPI) Scraper {
+	if !m.config.SiteAPIs {
+		return fallback
+	}
+	return NewAPIScraper(fallback.GetSiteInfo(), api, fallback, m.config.RequestTimeout)
+}
+
+// ProgressFunc is told when a site's scrape starts, with a nil result, and
+// again with the result once it finishes
+type ProgressFunc func(siteID string, result *models.ScrapeResult)
+
 func (m *Manager) ScrapeAll(ctx context.Context) map[string]models.ScrapeResult {
+	return m.ScrapeSites(ctx, m.SiteIDs(), nil)
+}
+
+// ScrapeSites scrapes the given sites concurrently, skipping unknown IDs and
+// reporting each site's progress when progress is set
+func (m *Manager) ScrapeSites(ctx context.Context, siteIDs []string, progress ProgressFunc) map[string]models.ScrapeResult {
+	m.mutex.RLock()
+	scrapers := make(map[string]Scraper, len(siteIDs))
+	for _, siteID := range siteIDs {
+		if scraper, exists := m.scrapers[siteID]; exists {
+			scrapers[siteID] = scraper
+		}
+	}
+	m.mutex.RUnlock()
+
 	results := make(map[string]models.ScrapeResult)
 	var wg sync.WaitGroup
-	resultsChan := make(chan models.ScrapeResult, len(m.scrapers))
+	resultsChan := make(chan models.ScrapeResult, len(scrapers))
 
 	// Limit concurrent scrapers
 	semaphore := make(chan struct{}, m.config.MaxConcurrentScrapers)
 
-	for siteID, scraper := range m.scrapers {
+	for siteID, scraper := range scrapers {
 		wg.Add(1)
 		go func(id string, s Scraper) {
 			defer wg.Done()
-			semaphore <- struct{}{} // Acquire
+			semaphore <- struct{}{}        // Acquire
 			defer func() { <-semaphore }() // Release
 
+			if progress != nil {
+				progress(id, nil)
+			}
 			result := m.scrapeWithTimeout(ctx, id, s)
+			if progress != nil {
+				progress(id, &result)
+			}
 			resultsChan <- result
 		}(siteID, scraper)
 	}
@@ -89,70 +321,473 @@ func (m *Manager) ScrapeAll(ctx context.Context) map[string]models.ScrapeResult
 		results[result.SiteID] = result
 	}
 
-	m.mutex.Lock()
-	for siteID, result := range results {
-		if m.results[siteID] == nil {
-			m.results[siteID] = make([]models.ScrapeResult, 0)
-		}
-		m.results[siteID] = append(m.results[siteID], result)
-		
-		// Keep only last 10 results per site
-		if len(m.results[siteID]) > 10 {
-			m.results[siteID] = m.results[siteID][1:]
-		}
+	for _, result := range results {
+		m.saveResult(result)
 	}
-	m.mutex.Unlock()
+
+	m.refreshArbitrage()
 
 	return results
 }
 
+// refreshArbitrage rescans the current best odds for arbitrage opportunities,
+// both on raw prices and on after-tax net prices
+func (m *Manager) refreshArbitrage() {
+	bestOdds := m.GetBestOdds()
+	arbitrage := analysis.FindArbitrage(bestOdds, m.arbitrageOptions(false))
+	netArbitrage := analysis.FindArbitrage(bestOdds, m.arbitrageOptions(true))
+
+	m.mutex.Lock()
+	m.arbitrage = arbitrage
+	m.netArbitrage = netArbitrage
+	m.mutex.Unlock()
+
+	if len(arbitrage) > 0 {
+		log.Printf("Found %d arbitrage opportunities, best %.2f%%", len(arbitrage), arbitrage[0].ProfitPercent)
+	}
+}
+
+// GetArbitrage returns the opportunities found after the last scrape, ranked
+// on after-tax net odds when net is set
+func (m *Manager) GetArbitrage(net bool) []models.Arbitrage {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	source := m.arbitrage
+	if net {
+		source = m.netArbitrage
+	}
+	arbitrage := make([]models.Arbitrage, len(source))
+	copy(arbitrage, source)
+	return arbitrage
+}
+
+// GetArbitrageFrom finds opportunities among prices of the given provenances
+// only, or returns every opportunity when none are given
+func (m *Manager) GetArbitrageFrom(net bool, provenances []string) []models.Arbitrage {
+	if len(provenances) == 0 {
+		return m.GetArbitrage(net)
+	}
+	return analysis.FindArbitrage(m.bestOdds(false, provenances), m.arbitrageOptions(net))
+}
+
+// arbitrageOptions are the configured arbitrage settings, taxed for net
+// arbitrage
+func (m *Manager) arbitrageOptions(net bool) analysis.ArbitrageOptions {
+	opts := analysis.ArbitrageOptions{
+		Stake:      m.config.ArbitrageStake,
+		MaxOddsAge: m.config.ArbitrageMaxOddsAge,
+	}
+	if net {
+		opts.Tax = m.taxProfiles()
+	}
+	return opts
+}
+
+// GetSites returns the registered betting sites with their tax profiles
+func (m *Manager) GetSites() []models.BettingSite {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	sites := make([]models.BettingSite, 0, len(m.sites))
+	for _, site := range m.sites {
+		sites = append(sites, site)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		return sites[i].ID < sites[j].ID
+	})
+	return sites
+}
+
+// SiteIDs returns the IDs of every registered site in order
+func (m *Manager) SiteIDs() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	ids := make([]string, 0, len(m.scrapers))
+	for id := range m.scrapers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// taxProfiles returns each registered site's tax profile keyed by site ID
+func (m *Manager) taxProfiles() map[string]models.TaxProfile {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	profiles := make(map[string]models.TaxProfile, len(m.sites))
+	for id, site := range m.sites {
+		profiles[id] = site.Tax
+	}
+	return profiles
+}
+
 func (m *Manager) scrapeWithTimeout(ctx context.Context, siteID string, scraper Scraper) models.ScrapeResult {
+	return m.runScrape(ctx, siteID, scraper.ScrapeOdds, nil)
+}
+
+// runScrape runs one scrape of a site and stores what it found. A targeted
+// scrape only refreshes part of the site, so the site's other odds are kept.
+func (m *Manager) runScrape(ctx context.Context, siteID string, scrape func(context.Context) ([]models.Match, []models.Odds, error), target *Target) models.ScrapeResult {
 	start := time.Now()
-	
-	timeoutCtx, cancel := context.WithTimeout(ctx, m.config.RequestTimeout)
-	defer cancel()
 
-	matches, odds, err := scraper.ScrapeOdds(timeoutCtx)
-	
+	var matches []models.Match
+	var odds []models.Odds
+	var err error
+	attempts := 0
+	breaker := m.breaker(siteID)
+	if breaker.Allow(start) {
+		matches, odds, attempts, err = m.attempt(ctx, siteID, scrape)
+		breaker.Record(err == nil, time.Now())
+	} else {
+		err = ErrCircuitOpen
+		if next := breaker.Status().NextProbe; next != nil {
+			err = fmt.Errorf("%w, next probe at %s", ErrCircuitOpen, next.Format(time.RFC3339))
+		}
+	}
+
 	result := models.ScrapeResult{
 		SiteID:    siteID,
-		Success:   err == nil,
+		Status:    models.ScrapeFailed,
+		Attempts:  attempts,
 		Duration:  time.Since(start),
 		ScrapedAt: time.Now(),
 	}
 
+	siteHistory := m.siteResults(siteID)
+
 	if err != nil {
 		result.Error = err.Error()
-		log.Printf("Failed to scrape %s: %v", siteID, err)
+		result.ErrorKind = Kind(err)
+		log.Printf("Failed to scrape %s (%s): %v", siteID, result.ErrorKind, err)
 	} else {
+		// Normalize names and map each site match onto its canonical fixture
+		canonicalIDs := make(map[string]string, len(matches))
+		for i, match := range matches {
+			resolved := m.resolver.Resolve(siteID, m.normalizer.Match(match))
+			canonicalIDs[match.ID] = resolved.ID
+			matches[i] = resolved
+		}
+		for i, odd := range odds {
+			odds[i].SyncMarkets()
+			if canonicalID, ok := canonicalIDs[odd.MatchID]; ok {
+				odds[i].SourceMatchID = odd.MatchID
+				odds[i].MatchID = canonicalID
+			}
+		}
+
+		// Check the scrape looks like the site's healthy scrapes before
+		// storing it; invalid prices are dropped here
+		expect := m.expectations(siteID)
+		var checks models.ScrapeChecks
+		odds, checks, result.Issues = validate(matches, odds, validation{
+			expect:   expect,
+			known:    func(league string) bool { return m.health.KnownLeague(league, expect) },
+			baseline: Baseline(siteHistory),
+			partial:  target != nil,
+		})
+		result.Checks = &checks
 		result.MatchCount = len(matches)
 		result.OddsCount = len(odds)
-		
+		result.Status = models.ScrapeOK
+		if len(result.Issues) > 0 {
+			result.Status = models.ScrapeDegraded
+		} else {
+			m.health.Learn(matches)
+		}
+
+		// Record every price that moved since the site's previous scrape
+		previous := m.siteOdds(siteID)
+		if err := m.store.SavePriceHistory(analysis.PriceChanges(previous, odds)); err != nil {
+			log.Printf("Failed to store price history for %s: %v", siteID, err)
+		}
+
 		// Store results
-		m.mutex.Lock()
-		for _, match := range matches {
-			m.matches[match.ID] = match
+		if err := m.store.SaveMatches(matches); err != nil {
+			log.Printf("Failed to store matches for %s: %v", siteID, err)
+		}
+		if target == nil {
+			m.saveOdds(siteID, odds)
+		} else {
+			m.saveOdds(siteID, mergeOdds(previous, odds, *target))
+		}
+
+		if result.Status == models.ScrapeDegraded {
+			log.Printf("Scraped %s with problems: %d matches, %d odds: %s", siteID, len(matches), len(odds), strings.Join(result.Issues, "; "))
+		} else {
+			log.Printf("Successfully scraped %s: %d matches, %d odds", siteID, len(matches), len(odds))
 		}
-		m.odds[siteID] = odds
-		m.mutex.Unlock()
-		
-		log.Printf("Successfully scraped %s: %d matches, %d odds", siteID, len(matches), len(odds))
 	}
 
+	result.Success = result.Status == models.ScrapeOK
+	result.Health = Score(siteHistory, result.Status)
+	var previous *models.ScrapeResult
+	if len(siteHistory) > 0 {
+		previous = &siteHistory[len(siteHistory)-1]
+	}
+	m.health.Observe(previous, result)
+	m.metrics.result(siteID, result.Status, result.ErrorKind, result.Health)
+
 	return result
 }
 
+// attempt scrapes a site, deciding from the kind of each failure what to do
+// next: timeouts, outages and rate limits are retried with backoff, bans
+// rotate the site's identity and are retried with the new one, and anything
+// else is given up on. Each attempt gets the full request timeout.
+func (m *Manager) attempt(ctx context.Context, siteID string, scrape func(context.Context) ([]models.Match, []models.Odds, error)) ([]models.Match, []models.Odds, int, error) {
+	for attempt := 1; ; attempt++ {
+		m.metrics.attempt(siteID, attempt > 1)
+
+		session, err := m.fixtureSession(siteID)
+		if err != nil {
+			return nil, nil, attempt, err
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, m.config.RequestTimeout)
+		attemptCtx = ratelimit.NewContext(fixtures.WithSession(attemptCtx, session), m.limiter)
+
+		// Replays make no requests, so they need no identity
+		var identity proxy.Identity
+		if !session.Replaying() {
+			if identity, err = m.proxies.Identity(siteID); err != nil {
+				cancel()
+				return nil, nil, attempt, err
+			}
+			attemptCtx = proxy.NewContext(attemptCtx, identity)
+		}
+		matches, odds, err := scrape(attemptCtx)
+		cancel()
+		m.saveRecording(session)
+		if session.Replaying() {
+			matches, odds = stamp(matches, odds, models.ProvenanceReplay)
+		}
+
+		kind := Kind(err)
+		rotated := false
+		if signal, ok := banSignal(err); ok && !session.Replaying() {
+			rotated = m.proxies.Report(siteID, identity, signal)
+		}
+		if err == nil || attempt > m.retry.Retries || !(retryable(kind) || rotated) || session.Replaying() {
+			return matches, odds, attempt, err
+		}
+		log.Printf("Scrape of %s failed on attempt %d (%s), retrying: %v", siteID, attempt, kind, err)
+		if !m.retry.wait(ctx, attempt) {
+			return nil, nil, attempt, err
+		}
+	}
+}
+
+// breaker returns a site's circuit breaker
+func (m *Manager) breaker(siteID string) *Breaker {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	breaker, exists := m.breakers[siteID]
+	if !exists {
+		breaker = NewBreaker(m.config.BreakerThreshold, m.config.BreakerCooldown)
+		m.breakers[siteID] = breaker
+	}
+	return breaker
+}
+
+// BreakerStatus returns the state of a site's circuit breaker
+func (m *Manager) BreakerStatus(siteID string) BreakerStatus {
+	return m.breaker(siteID).Status()
+}
+
+// Metrics returns every site's scrape counters and breaker state
+func (m *Manager) Metrics() []SiteMetrics {
+	metrics := m.metrics.snapshot()
+	seen := make(map[string]bool, len(metrics))
+	for i := range metrics {
+		metrics[i].Breaker = m.BreakerStatus(metrics[i].SiteID)
+		seen[metrics[i].SiteID] = true
+	}
+	// Sites not scraped yet still report their breaker
+	for _, siteID := range m.SiteIDs() {
+		if !seen[siteID] {
+			metrics = append(metrics, SiteMetrics{SiteID: siteID, Results: map[string]int{}, Breaker: m.BreakerStatus(siteID)})
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].SiteID < metrics[j].SiteID
+	})
+	return metrics
+}
+
+// RateLimits returns the request budget and counters of each bookmaker domain
+func (m *Manager) RateLimits() []ratelimit.DomainStats {
+	return m.limiter.Stats()
+}
+
+// Proxies returns the health, bans and assigned sites of each proxy
+func (m *Manager) Proxies() []proxy.Status {
+	return m.proxies.Status()
+}
+
+// Identities returns the proxy and browser profile each site is using
+func (m *Manager) Identities() []proxy.SiteIdentity {
+	return m.proxies.Assignments()
+}
+
+// expectations returns what a healthy scrape of a site looks like
+func (m *Manager) expectations(siteID string) Expectations {
+	defaults := defaultExpectations(m.config)
+
+	m.mutex.RLock()
+	scraper, ok := m.scrapers[siteID].(ExpectingScraper)
+	m.mutex.RUnlock()
+	if !ok {
+		return defaults
+	}
+	return scraper.Expectations().withDefaults(defaults)
+}
+
+// fixtureSession returns a session that records a site's scrape, or that
+// replays the site's latest recording, in the matching fixtures mode
+func (m *Manager) fixtureSession(siteID string) (*fixtures.Session, error) {
+	switch m.config.FixturesMode {
+	case fixtures.ModeRecord:
+		return fixtures.NewRecorder(siteID), nil
+	case fixtures.ModeReplay:
+		recording, err := fixtures.Latest(m.config.FixturesDir, siteID)
+		if err != nil {
+			return nil, err
+		}
+		return fixtures.NewReplay(recording), nil
+	}
+	return nil, nil
+}
+
+// saveRecording writes out what a recording session fetched
+func (m *Manager) saveRecording(session *fixtures.Session) {
+	if session == nil || session.Replaying() {
+		return
+	}
+	recording := session.Recording()
+	if len(recording.Artifacts) == 0 {
+		return
+	}
+	path, err := fixtures.Save(m.config.FixturesDir, recording)
+	if err != nil {
+		log.Printf("Failed to save %s recording: %v", recording.SiteID, err)
+		return
+	}
+	log.Printf("Recorded %d artifacts for %s in %s", len(recording.Artifacts), recording.SiteID, path)
+}
+
+// mergeOdds replaces the targeted part of a site's previous odds with the
+// fresh odds from a targeted scrape
+func mergeOdds(previous, fresh []models.Odds, target Target) []models.Odds {
+	replaced := make(map[string]bool)
+	for _, odd := range fresh {
+		replaced[odd.MatchID] = true
+	}
+	for _, id := range target.MatchIDs {
+		replaced[id] = true
+	}
+
+	merged := make([]models.Odds, 0, len(previous)+len(fresh))
+	for _, odd := range previous {
+		if !replaced[odd.MatchID] && !replaced[odd.SourceMatchID] {
+			merged = append(merged, odd)
+		}
+	}
+	return append(merged, fresh...)
+}
+
+// ScrapeTargets refreshes selected fixtures or leagues of one site. It
+// reports false for unknown sites and sites whose scraper cannot target.
+func (m *Manager) ScrapeTargets(ctx context.Context, siteID string, target Target) (models.ScrapeResult, bool) {
+	m.mutex.RLock()
+	targeted, ok := m.scrapers[siteID].(TargetedScraper)
+	m.mutex.RUnlock()
+	if !ok {
+		return models.ScrapeResult{}, false
+	}
+
+	result := m.runScrape(ctx, siteID, func(ctx context.Context) ([]models.Match, []models.Odds, error) {
+		return targeted.ScrapeTargets(ctx, target)
+	}, &target)
+
+	m.saveResult(result)
+	m.refreshArbitrage()
+	return result, true
+}
+
+// ScrapeTimeout is how long a scrape of the given number of sites can take:
+// the sites run MAX_CONCURRENT_SCRAPERS at a time, and each may use every
+// attempt's full request timeout and the longest backoff between them
+func (m *Manager) ScrapeTimeout(sites int) time.Duration {
+	batches := 1
+	if limit := m.config.MaxConcurrentScrapers; limit > 0 && sites > limit {
+		batches = (sites + limit - 1) / limit
+	}
+	return time.Duration(batches) * m.retry.Budget(m.config.RequestTimeout)
+}
+
+// CanTarget reports whether a site's scraper supports targeted scrapes
+func (m *Manager) CanTarget(siteID string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	_, ok := m.scrapers[siteID].(TargetedScraper)
+	return ok
+}
+
+// UpcomingMatches returns every stored fixture that has not kicked off yet
+func (m *Manager) UpcomingMatches() []models.Match {
+	matches, err := m.store.Matches()
+	if err != nil {
+		log.Printf("Failed to load matches: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	upcoming := make([]models.Match, 0, len(matches))
+	for _, match := range matches {
+		if match.MatchTime.After(now) {
+			upcoming = append(upcoming, match)
+		}
+	}
+	return upcoming
+}
+
 func (m *Manager) GetBestOdds() []models.BestOdds {
+	return m.bestOdds(false, nil)
+}
+
+// GetNetBestOdds ranks prices on after-tax net odds for each site, returning
+// both the raw and the net price for every best selection
+func (m *Manager) GetNetBestOdds() []models.BestOdds {
+	return m.bestOdds(true, nil)
+}
+
+// GetBestOddsFrom ranks only prices of the given provenances, such as live
+// and api to leave out simulated prices. No provenances means every price.
+func (m *Manager) GetBestOddsFrom(net bool, provenances []string) []models.BestOdds {
+	return m.bestOdds(net, provenances)
+}
+
+func (m *Manager) bestOdds(net bool, provenances []string) []models.BestOdds {
+	matches, err := m.store.Matches()
+	if err != nil {
+		log.Printf("Failed to load matches: %v", err)
+		return []models.BestOdds{}
+	}
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	bestOddsMap := make(map[string]*models.BestOdds)
+	bestPrices := make(map[string]map[string]*models.BestPrice)
 
 	// Process all odds for each match
 	for _, odds := range m.odds {
 		for _, odd := range odds {
-			match, exists := m.matches[odd.MatchID]
-			if !exists {
+			match, exists := matches[odd.MatchID]
+			if !exists || (len(provenances) > 0 && !slices.Contains(provenances, odd.Provenance)) {
 				continue
 			}
 
@@ -162,54 +797,227 @@ func (m *Manager) GetBestOdds() []models.BestOdds {
 					AllOdds:   make([]models.Odds, 0),
 					UpdatedAt: time.Now(),
 				}
+				bestPrices[odd.MatchID] = make(map[string]*models.BestPrice)
+			}
+
+			if net {
+				odd = withNetPrices(odd, m.sites[odd.SiteID].Tax)
 			}
 
 			bestOdd := bestOddsMap[odd.MatchID]
 			bestOdd.AllOdds = append(bestOdd.AllOdds, odd)
 
-			// Update best odds
-			if bestOdd.BestHomeWin == nil || odd.HomeWin > bestOdd.BestHomeWin.Value {
-				bestOdd.BestHomeWin = &models.OddsComparison{
-					Value:    odd.HomeWin,
-					SiteID:   odd.SiteID,
-					SiteName: odd.SiteName,
+			// Track the best price per market, line and selection
+			best := bestPrices[odd.MatchID]
+			for _, price := range odd.Markets {
+				if price.Value <= 0 {
+					continue
 				}
-			}
-
-			if odd.Draw > 0 && (bestOdd.BestDraw == nil || odd.Draw > bestOdd.BestDraw.Value) {
-				bestOdd.BestDraw = &models.OddsComparison{
-					Value:    odd.Draw,
-					SiteID:   odd.SiteID,
-					SiteName: odd.SiteName,
-				}
-			}
-
-			if bestOdd.BestAwayWin == nil || odd.AwayWin > bestOdd.BestAwayWin.Value {
-				bestOdd.BestAwayWin = &models.OddsComparison{
-					Value:    odd.AwayWin,
-					SiteID:   odd.SiteID,
-					SiteName: odd.SiteName,
+				key := price.Key()
+				if best[key] == nil || rankValue(price) > rankValue(best[key].Price) {
+					best[key] = &models.BestPrice{
+						Price:      price,
+						SiteID:     odd.SiteID,
+						SiteName:   odd.SiteName,
+						Provenance: odd.Provenance,
+					}
 				}
 			}
 		}
 	}
 
 	result := make([]models.BestOdds, 0, len(bestOddsMap))
-	for _, bestOdd := range bestOddsMap {
+	for matchID, bestOdd := range bestOddsMap {
+		best := bestPrices[matchID]
+		keys := make([]string, 0, len(best))
+		for key := range best {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			bestOdd.BestPrices = append(bestOdd.BestPrices, *best[key])
+		}
+
+		bestOdd.BestHomeWin = comparison(best, models.MarketMatchResult, "", models.SelectionHome)
+		bestOdd.BestDraw = comparison(best, models.MarketMatchResult, "", models.SelectionDraw)
+		bestOdd.BestAwayWin = comparison(best, models.MarketMatchResult, "", models.SelectionAway)
+		bestOdd.BestOver25 = comparison(best, models.MarketOverUnder, "2.5", models.SelectionOver)
+		bestOdd.BestUnder25 = comparison(best, models.MarketOverUnder, "2.5", models.SelectionUnder)
+		bestOdd.BestBTTS = comparison(best, models.MarketBTTS, "", models.SelectionYes)
+		bestOdd.BestBTTSNo = comparison(best, models.MarketBTTS, "", models.SelectionNo)
+
 		result = append(result, *bestOdd)
 	}
 
 	return result
 }
 
+// comparison returns the best full-time price for a selection in the legacy
+// comparison shape, or nil when no site offers it
+func comparison(best map[string]*models.BestPrice, market, line, selection string) *models.OddsComparison {
+	key := models.Price{Market: market, Line: line, Period: models.PeriodFullTime, Selection: selection}.Key()
+	price, exists := best[key]
+	if !exists {
+		return nil
+	}
+	return &models.OddsComparison{
+		Value:      price.Value,
+		NetValue:   price.NetValue,
+		SiteID:     price.SiteID,
+		SiteName:   price.SiteName,
+		Provenance: price.Provenance,
+	}
+}
+
+// withNetPrices returns a copy of the odds with every price's net value set
+func withNetPrices(odd models.Odds, tax models.TaxProfile) models.Odds {
+	markets := make([]models.Price, len(odd.Markets))
+	for i, price := range odd.Markets {
+		price.NetValue = analysis.NetOdds(price.Value, tax)
+		markets[i] = price
+	}
+	odd.Markets = markets
+	return odd
+}
+
+// rankValue is the value prices are compared on: net when computed, else raw
+func rankValue(price models.Price) float64 {
+	if price.NetValue > 0 {
+		return price.NetValue
+	}
+	return price.Value
+}
+
+// GetMatchHistory returns the odds movement of every selection on a fixture
+func (m *Manager) GetMatchHistory(matchID string) (models.MatchHistory, bool, error) {
+	matches, err := m.store.Matches()
+	if err != nil {
+		return models.MatchHistory{}, false, err
+	}
+	match, exists := matches[matchID]
+	if !exists {
+		return models.MatchHistory{}, false, nil
+	}
+
+	points, err := m.store.PriceHistory(matchID)
+	if err != nil {
+		return models.MatchHistory{}, false, err
+	}
+
+	m.mutex.RLock()
+	siteNames := make(map[string]string, len(m.sites))
+	for id, site := range m.sites {
+		siteNames[id] = site.Name
+	}
+	m.mutex.RUnlock()
+
+	return models.MatchHistory{
+		Match:     match,
+		Movements: analysis.PriceMovements(points, siteNames),
+	}, true, nil
+}
+
+// Normalizer returns the team and league name normalizer
+func (m *Manager) Normalizer() *normalize.Normalizer {
+	return m.normalizer
+}
+
+// PruneFixtures drops fixtures that kicked off before the given time, along
+// with their odds
+func (m *Manager) PruneFixtures(before time.Time) int {
+	matches, err := m.store.Matches()
+	if err != nil {
+		log.Printf("Failed to load matches: %v", err)
+	}
+	if _, err := m.store.DeleteMatchesBefore(before); err != nil {
+		log.Printf("Failed to delete finished matches: %v", err)
+	}
+
+	m.mutex.Lock()
+	for siteID, odds := range m.odds {
+		kept := make([]models.Odds, 0, len(odds))
+		for _, odd := range odds {
+			if match, exists := matches[odd.MatchID]; !exists || !match.MatchTime.Before(before) {
+				kept = append(kept, odd)
+			}
+		}
+		m.odds[siteID] = kept
+	}
+	m.mutex.Unlock()
+
+	return m.resolver.Prune(before)
+}
+
+// GetAlerts returns the recent scrape health alerts, newest first
+func (m *Manager) GetAlerts() []models.Alert {
+	return m.health.Alerts()
+}
+
 func (m *Manager) GetScrapeResults() map[string][]models.ScrapeResult {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	
-	results := make(map[string][]models.ScrapeResult)
-	for k, v := range m.results {
-		results[k] = make([]models.ScrapeResult, len(v))
-		copy(results[k], v)
+
+	results := make(map[string][]models.ScrapeResult, len(m.results))
+	for siteID, siteResults := range m.results {
+		results[siteID] = append([]models.ScrapeResult(nil), siteResults...)
 	}
 	return results
-}
\ No newline at end of file
+}
+
+// siteOdds returns a site's current odds
+func (m *Manager) siteOdds(siteID string) []models.Odds {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.odds[siteID]
+}
+
+// saveOdds replaces a site's current odds, writing them through to the store
+func (m *Manager) saveOdds(siteID string, odds []models.Odds) {
+	if err := m.store.SaveOdds(siteID, odds); err != nil {
+		log.Printf("Failed to store odds for %s: %v", siteID, err)
+	}
+	m.mutex.Lock()
+	m.odds[siteID] = odds
+	m.mutex.Unlock()
+}
+
+// siteResults returns a site's recent scrape results, oldest first
+func (m *Manager) siteResults(siteID string) []models.ScrapeResult {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return append([]models.ScrapeResult(nil), m.results[siteID]...)
+}
+
+// saveResult records a scrape result, writing it through to the store
+func (m *Manager) saveResult(result models.ScrapeResult) {
+	if err := m.store.SaveScrapeResult(result); err != nil {
+		log.Printf("Failed to store scrape result for %s: %v", result.SiteID, err)
+	}
+	m.mutex.Lock()
+	results := append(m.results[result.SiteID], result)
+	if len(results) > resultHistory {
+		results = results[len(results)-resultHistory:]
+	}
+	m.results[result.SiteID] = results
+	m.mutex.Unlock()
+}
+
+// BrowserStats describes the shared browser pool, or returns nil when no
+// scraper uses a browser
+func (m *Manager) BrowserStats() *browser.Stats {
+	if m.browsers == nil {
+		return nil
+	}
+	stats := m.browsers.Stats()
+	return &stats
+}
+
+// Close stops the proxy health checks, shuts down the browser pool and
+// releases the underlying store
+func (m *Manager) Close() error {
+	m.proxies.Close()
+	if m.browsers != nil {
+		m.browsers.Close()
+	}
+	return m.store.Close()
+}
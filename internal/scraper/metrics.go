@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"sort"
+	"sync"
+)
+
+// SiteMetrics are the scrape counters and breaker state of one site since
+// startup
+type SiteMetrics struct {
+	SiteID   string
+	Attempts int
+	Retries  int
+	Results  map[string]int
+	// Errors counts failed scrapes by error kind
+	Errors  map[string]int
+	Health  float64
+	Breaker BreakerStatus
+}
+
+// scrapeMetrics counts scrape attempts and outcomes per site
+type scrapeMetrics struct {
+	mutex sync.Mutex
+	sites map[string]*SiteMetrics
+}
+
+func newScrapeMetrics() *scrapeMetrics {
+	return &scrapeMetrics{sites: make(map[string]*SiteMetrics)}
+}
+
+// site returns a site's counters; the caller must hold the lock
+func (s *scrapeMetrics) site(siteID string) *SiteMetrics {
+	metrics, exists := s.sites[siteID]
+	if !exists {
+		metrics = &SiteMetrics{SiteID: siteID, Results: make(map[string]int), Errors: make(map[string]int)}
+		s.sites[siteID] = metrics
+	}
+	return metrics
+}
+
+// attempt counts one scrape attempt, and a retry when it is not the first
+func (s *scrapeMetrics) attempt(siteID string, retry bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metrics := s.site(siteID)
+	metrics.Attempts++
+	if retry {
+		metrics.Retries++
+	}
+}
+
+// result counts a finished scrape by status and error kind, and records the
+// site's health
+func (s *scrapeMetrics) result(siteID, status, errorKind string, health float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metrics := s.site(siteID)
+	metrics.Results[status]++
+	if errorKind != "" {
+		metrics.Errors[errorKind]++
+	}
+	metrics.Health = health
+}
+
+// snapshot copies every site's counters, in site order
+func (s *scrapeMetrics) snapshot() []SiteMetrics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := make([]SiteMetrics, 0, len(s.sites))
+	for _, metrics := range s.sites {
+		copied := *metrics
+		copied.Results = make(map[string]int, len(metrics.Results))
+		for status, count := range metrics.Results {
+			copied.Results[status] = count
+		}
+		copied.Errors = make(map[string]int, len(metrics.Errors))
+		for kind, count := range metrics.Errors {
+			copied.Errors[kind] = count
+		}
+		snapshot = append(snapshot, copied)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].SiteID < snapshot[j].SiteID
+	})
+	return snapshot
+}
@@ -0,0 +1,14 @@
+package scraper
+
+import "betting-odds-scraper/internal/models"
+
+// stamp records where a scrape's matches and odds came from
+func stamp(matches []models.Match, odds []models.Odds, provenance string) ([]models.Match, []models.Odds) {
+	for i := range matches {
+		matches[i].Provenance = provenance
+	}
+	for i := range odds {
+		odds[i].Provenance = provenance
+	}
+	return matches, odds
+}
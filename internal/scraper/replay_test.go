@@ -0,0 +1,205 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/fixtures"
+	"betting-odds-scraper/internal/models"
+	"betting-odds-scraper/internal/simulation"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files from the current parsers")
+
+// replayScrapers builds every live scraper the way the manager does, without
+// a browser pool since replays never reach the network
+func replayScrapers(t *testing.T) map[string]Scraper {
+	t.Helper()
+	scrapers := map[string]Scraper{
+		"betika":    NewAPIScraper(NewBetikaScraper(nil, "").GetSiteInfo(), BetikaAPI(""), NewBetikaScraper(nil, ""), time.Second),
+		"sportpesa": NewAPIScraper(NewSportPesaScraper(nil, "").GetSiteInfo(), SportPesaAPI(""), NewSportPesaScraper(nil, ""), time.Second),
+	}
+
+	definitions, err := LoadSiteDefinitions(filepath.Join("..", "..", "sites"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, def := range definitions {
+		scrapers[def.ID] = NewGenericScraper(def, nil)
+	}
+	return scrapers
+}
+
+// golden is what a replayed scrape is expected to parse
+type golden struct {
+	Matches []models.Match `json:"matches"`
+	Odds    []models.Odds  `json:"odds"`
+}
+
+// TestReplayGolden replays every recording in testdata/replay through its
+// site's scraper and compares the result with testdata/golden. Run with
+// -update after an intended parser change to rewrite the golden files.
+func TestReplayGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "replay", "*", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no recordings found")
+	}
+	scrapers := replayScrapers(t)
+
+	for _, path := range paths {
+		name := filepath.Base(filepath.Dir(path)) + "_" + filepath.Base(path)
+		t.Run(name, func(t *testing.T) {
+			recording, err := fixtures.Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scraper, exists := scrapers[recording.SiteID]
+			if !exists {
+				t.Fatalf("no scraper for %s", recording.SiteID)
+			}
+
+			ctx := fixtures.WithSession(context.Background(), fixtures.NewReplay(recording))
+			matches, odds, err := scraper.ScrapeOdds(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(golden{Matches: matches, Odds: odds}, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			goldenPath := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("parsed result differs from %s:\n%s", goldenPath, got)
+			}
+		})
+	}
+}
+
+func TestReplayExhausted(t *testing.T) {
+	// A recording without the API call the scraper makes cannot be replayed
+	recording := fixtures.Recording{SiteID: "sportpesa", RecordedAt: time.Now()}
+	ctx := fixtures.WithSession(context.Background(), fixtures.NewReplay(recording))
+
+	site := NewSportPesaScraper(nil, "").GetSiteInfo()
+	if _, _, err := NewAPIScraper(site, SportPesaAPI(""), nil, time.Second).ScrapeOdds(ctx); err == nil {
+		t.Fatal("expected an error replaying an empty recording")
+	}
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	server := fixtureServer(t, "sportpesa_games.json", `[]`)
+	site := NewSportPesaScraper(nil, "").GetSiteInfo()
+	scraper := NewAPIScraper(site, SportPesaAPI(server.URL), nil, time.Second)
+
+	session := fixtures.NewRecorder(site.ID)
+	recorded, _, err := scraper.ScrapeOdds(fixtures.WithSession(context.Background(), session))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if _, err := fixtures.Save(dir, session.Recording()); err != nil {
+		t.Fatal(err)
+	}
+	recording, err := fixtures.Latest(dir, site.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both pages are recorded: the fixture and the empty page that ends paging
+	if len(recording.Artifacts) != 2 {
+		t.Fatalf("recorded %d artifacts, want 2", len(recording.Artifacts))
+	}
+
+	// The replay needs no server
+	server.Close()
+	replayed, _, err := scraper.ScrapeOdds(fixtures.WithSession(context.Background(), fixtures.NewReplay(recording)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != len(recorded) {
+		t.Fatalf("replayed %d matches, recorded %d", len(replayed), len(recorded))
+	}
+}
+
+func TestNoSampleData(t *testing.T) {
+	// A page whose odds calls were missed yields an error, not made-up matches
+	page := `<html><body><div id="app"></div><script src="/app.js"></script></body></html>`
+	scrapers := []Scraper{
+		NewBetikaScraper(nil, ""),
+		NewSportPesaScraper(nil, ""),
+	}
+	for _, scraper := range scrapers {
+		site := scraper.GetSiteInfo()
+		recording := fixtures.Recording{
+			SiteID:     site.ID,
+			RecordedAt: time.Now(),
+			Artifacts:  []fixtures.Artifact{{Kind: fixtures.KindPage, Body: []byte(page)}},
+		}
+		ctx := fixtures.WithSession(context.Background(), fixtures.NewReplay(recording))
+
+		matches, odds, err := scraper.ScrapeOdds(ctx)
+		if err == nil || len(matches) > 0 || len(odds) > 0 {
+			t.Errorf("%s: got %d matches, %d odds and %v, want an error", site.ID, len(matches), len(odds), err)
+		}
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	server := fixtureServer(t, "sportpesa_games.json", `[]`)
+	site := NewSportPesaScraper(nil, "").GetSiteInfo()
+	matches, odds, err := NewAPIScraper(site, SportPesaAPI(server.URL), nil, time.Second).ScrapeOdds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range matches {
+		if match.Provenance != models.ProvenanceAPI {
+			t.Errorf("match %s has provenance %q, want api", match.ID, match.Provenance)
+		}
+	}
+	for _, odd := range odds {
+		if odd.Provenance != models.ProvenanceAPI {
+			t.Errorf("odds %s have provenance %q, want api", odd.ID, odd.Provenance)
+		}
+	}
+
+	market := simulation.New(1, demoBooks, time.Now())
+	matches, odds, _ = NewDemoScraper(market, "betika", "Betika").ScrapeTargets(context.Background(), Target{Leagues: []string{"Serie A"}})
+	if len(matches) == 0 || matches[0].Provenance != models.ProvenanceDemo || odds[0].Provenance != models.ProvenanceDemo {
+		t.Errorf("demo data is not marked demo: %+v", matches)
+	}
+
+	// A fixture keeps its most direct provenance whichever site came last
+	resolver := NewMatchResolver(time.Hour)
+	kickoff := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	match := models.Match{ID: "a", Sport: "football", HomeTeam: "Arsenal", AwayTeam: "Chelsea", MatchTime: kickoff, Provenance: models.ProvenanceLive}
+	resolver.Resolve("betika", match)
+	match.ID, match.Provenance = "b", models.ProvenanceReplay
+	if resolved := resolver.Resolve("sportpesa", match); resolved.Provenance != models.ProvenanceLive {
+		t.Errorf("fixture listed live by betika has provenance %q", resolved.Provenance)
+	}
+}
@@ -0,0 +1,178 @@
+package scraper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// MatchResolver maps each site's match onto a shared canonical fixture so
+// that odds from different bookmakers for the same game can be compared.
+type MatchResolver struct {
+	window   time.Duration
+	fixtures map[string][]*fixture
+	aliases  map[string]string
+	mutex    sync.Mutex
+}
+
+// fixture is a canonical match together with the per-site IDs that map to it
+// and where each site's listing came from
+type fixture struct {
+	id          string
+	matchTime   time.Time
+	sources     map[string]string
+	provenances map[string]string
+}
+
+// NewMatchResolver creates a resolver that treats matches with the same sport
+// and teams as one fixture when their kickoff times are within window.
+func NewMatchResolver(window time.Duration) *MatchResolver {
+	return &MatchResolver{
+		window:   window,
+		fixtures: make(map[string][]*fixture),
+		aliases:  make(map[string]string),
+	}
+}
+
+// Resolve returns the match rewritten to its canonical fixture ID, recording
+// the site's own match ID as an alias of that fixture.
+func (r *MatchResolver) Resolve(siteID string, match models.Match) models.Match {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := fixtureKey(match.Sport, match.HomeTeam, match.AwayTeam)
+
+	var found *fixture
+	for _, f := range r.fixtures[key] {
+		diff := f.matchTime.Sub(match.MatchTime)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= r.window {
+			found = f
+			break
+		}
+	}
+
+	if found == nil {
+		found = &fixture{
+			id:          fmt.Sprintf("%s_%s", key, match.MatchTime.UTC().Format("200601021504")),
+			matchTime:   match.MatchTime,
+			sources:     make(map[string]string),
+			provenances: make(map[string]string),
+		}
+		r.fixtures[key] = append(r.fixtures[key], found)
+	}
+
+	found.sources[siteID] = match.ID
+	found.provenances[siteID] = match.Provenance
+	r.aliases[aliasKey(siteID, match.ID)] = found.id
+
+	resolved := match
+	resolved.ID = found.id
+	resolved.MatchTime = found.matchTime
+	resolved.Provenance = found.provenance()
+	resolved.SourceIDs = make(map[string]string, len(found.sources))
+	for site, sourceID := range found.sources {
+		resolved.SourceIDs[site] = sourceID
+	}
+	return resolved
+}
+
+// Seed restores a fixture resolved in an earlier run, so that site matches
+// keep mapping to the same canonical ID across restarts
+func (r *MatchResolver) Seed(match models.Match) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := fixtureKey(match.Sport, match.HomeTeam, match.AwayTeam)
+	for _, f := range r.fixtures[key] {
+		if f.id == match.ID {
+			return
+		}
+	}
+
+	seeded := &fixture{
+		id:          match.ID,
+		matchTime:   match.MatchTime,
+		sources:     make(map[string]string, len(match.SourceIDs)),
+		provenances: make(map[string]string, len(match.SourceIDs)),
+	}
+	for site, sourceID := range match.SourceIDs {
+		seeded.sources[site] = sourceID
+		seeded.provenances[site] = match.Provenance
+		r.aliases[aliasKey(site, sourceID)] = match.ID
+	}
+	r.fixtures[key] = append(r.fixtures[key], seeded)
+}
+
+// CanonicalID returns the fixture ID a site's match ID was resolved to
+func (r *MatchResolver) CanonicalID(siteID, sourceID string) (string, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id, ok := r.aliases[aliasKey(siteID, sourceID)]
+	return id, ok
+}
+
+// Prune forgets fixtures that kicked off before the given time
+func (r *MatchResolver) Prune(before time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := 0
+	for key, fixtures := range r.fixtures {
+		kept := fixtures[:0]
+		for _, f := range fixtures {
+			if f.matchTime.Before(before) {
+				for site, sourceID := range f.sources {
+					delete(r.aliases, aliasKey(site, sourceID))
+				}
+				removed++
+				continue
+			}
+			kept = append(kept, f)
+		}
+		if len(kept) == 0 {
+			delete(r.fixtures, key)
+		} else {
+			r.fixtures[key] = kept
+		}
+	}
+	return removed
+}
+
+// provenance is the most direct source any site listed the fixture from, in
+// the order of models.Provenances, so a fixture one book lists live is live
+// whichever site was resolved last
+func (f *fixture) provenance() string {
+	best := ""
+	for _, provenance := range f.provenances {
+		rank := slices.Index(models.Provenances, provenance)
+		if rank >= 0 && (best == "" || rank < slices.Index(models.Provenances, best)) {
+			best = provenance
+		}
+	}
+	return best
+}
+
+func aliasKey(siteID, sourceID string) string {
+	return siteID + ":" + sourceID
+}
+
+func fixtureKey(sport, home, away string) string {
+	return fmt.Sprintf("%s_%s_vs_%s", normalizeName(sport), normalizeName(home), normalizeName(away))
+}
+
+// normalizeName lowercases a name and reduces it to words joined by underscores
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "_")
+}
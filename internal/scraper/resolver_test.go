@@ -0,0 +1,131 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+var resolverKickoff = time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+
+func siteMatch(id, home, away string, kickoff time.Time) models.Match {
+	return models.Match{ID: id, Sport: "football", HomeTeam: home, AwayTeam: away, League: "Premier League", MatchTime: kickoff}
+}
+
+func TestResolve(t *testing.T) {
+	const window = 2 * time.Hour
+	first := siteMatch("betika_1", "Arsenal", "Chelsea", resolverKickoff)
+	firstID := "football_arsenal_vs_chelsea_202610181500"
+
+	tests := []struct {
+		name   string
+		match  models.Match
+		wantID string
+	}{
+		{"same kickoff", siteMatch("sportpesa_1", "Arsenal", "Chelsea", resolverKickoff), firstID},
+		{"spelling and case", siteMatch("sportpesa_1", "ARSENAL", "chelsea", resolverKickoff), firstID},
+		{"just inside the window after", siteMatch("sportpesa_1", "Arsenal", "Chelsea", resolverKickoff.Add(window)), firstID},
+		{"just inside the window before", siteMatch("sportpesa_1", "Arsenal", "Chelsea", resolverKickoff.Add(-window)), firstID},
+		{"just outside the window after", siteMatch("sportpesa_1", "Arsenal", "Chelsea", resolverKickoff.Add(window+time.Minute)), "football_arsenal_vs_chelsea_202610181701"},
+		{"just outside the window before", siteMatch("sportpesa_1", "Arsenal", "Chelsea", resolverKickoff.Add(-window-time.Minute)), "football_arsenal_vs_chelsea_202610181259"},
+		// The reverse fixture is another game
+		{"swapped home and away", siteMatch("sportpesa_1", "Chelsea", "Arsenal", resolverKickoff), "football_chelsea_vs_arsenal_202610181500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMatchResolver(window)
+			r.Resolve("betika", first)
+
+			resolved := r.Resolve("sportpesa", tt.match)
+			if resolved.ID != tt.wantID {
+				t.Fatalf("resolved to %s, want %s", resolved.ID, tt.wantID)
+			}
+			if id, ok := r.CanonicalID("sportpesa", tt.match.ID); !ok || id != tt.wantID {
+				t.Errorf("CanonicalID = %s, %v; want %s", id, ok, tt.wantID)
+			}
+
+			// A match joining a fixture takes its kickoff and lists both sites
+			if tt.wantID == firstID {
+				if !resolved.MatchTime.Equal(resolverKickoff) {
+					t.Errorf("kickoff %v, want the fixture's %v", resolved.MatchTime, resolverKickoff)
+				}
+				if len(resolved.SourceIDs) != 2 || resolved.SourceIDs["betika"] != "betika_1" || resolved.SourceIDs["sportpesa"] != "sportpesa_1" {
+					t.Errorf("source IDs %v, want both sites", resolved.SourceIDs)
+				}
+			} else if len(resolved.SourceIDs) != 1 {
+				t.Errorf("source IDs %v, want only sportpesa", resolved.SourceIDs)
+			}
+		})
+	}
+}
+
+func TestResolveSeed(t *testing.T) {
+	// A fixture stored by an earlier run keeps its ID and aliases, even if a
+	// new resolution would name it differently
+	stored := siteMatch("football_arsenal_vs_chelsea_202610181430", "Arsenal", "Chelsea", resolverKickoff.Add(-30*time.Minute))
+	stored.SourceIDs = map[string]string{"betika": "betika_1"}
+	stored.Provenance = models.ProvenanceLive
+
+	r := NewMatchResolver(2 * time.Hour)
+	r.Seed(stored)
+	r.Seed(stored)
+	if id, ok := r.CanonicalID("betika", "betika_1"); !ok || id != stored.ID {
+		t.Errorf("seeded alias resolves to %s, %v", id, ok)
+	}
+
+	resolved := r.Resolve("sportpesa", siteMatch("sportpesa_1", "Arsenal", "Chelsea", resolverKickoff))
+	if resolved.ID != stored.ID || !resolved.MatchTime.Equal(stored.MatchTime) {
+		t.Errorf("resolved to %s at %v, want the seeded %s", resolved.ID, resolved.MatchTime, stored.ID)
+	}
+	if len(resolved.SourceIDs) != 2 || resolved.Provenance != models.ProvenanceLive {
+		t.Errorf("resolved %+v, want both sites and live provenance", resolved)
+	}
+}
+
+func TestResolvePrune(t *testing.T) {
+	r := NewMatchResolver(2 * time.Hour)
+	r.Resolve("betika", siteMatch("betika_1", "Arsenal", "Chelsea", resolverKickoff))
+	r.Resolve("betika", siteMatch("betika_2", "Arsenal", "Chelsea", resolverKickoff.Add(7*24*time.Hour)))
+	r.Resolve("sportpesa", siteMatch("sportpesa_3", "Liverpool", "Everton", resolverKickoff.Add(time.Hour)))
+
+	tests := []struct {
+		before  time.Time
+		removed int
+		gone    []string
+		kept    []string
+	}{
+		// Kicking off at the cutoff is not before it
+		{resolverKickoff, 0, nil, []string{"betika_1", "betika_2", "sportpesa_3"}},
+		{resolverKickoff.Add(time.Minute), 1, []string{"betika_1"}, []string{"betika_2", "sportpesa_3"}},
+		{resolverKickoff.Add(2 * time.Hour), 1, []string{"betika_1", "sportpesa_3"}, []string{"betika_2"}},
+	}
+	for _, tt := range tests {
+		if removed := r.Prune(tt.before); removed != tt.removed {
+			t.Errorf("Prune(%v) removed %d, want %d", tt.before, removed, tt.removed)
+		}
+		for _, sourceID := range tt.gone {
+			if _, ok := r.CanonicalID(siteOf(sourceID), sourceID); ok {
+				t.Errorf("after Prune(%v) %s is still known", tt.before, sourceID)
+			}
+		}
+		for _, sourceID := range tt.kept {
+			if _, ok := r.CanonicalID(siteOf(sourceID), sourceID); !ok {
+				t.Errorf("after Prune(%v) %s is gone", tt.before, sourceID)
+			}
+		}
+	}
+
+	// A pruned fixture's teams can start a new fixture
+	resolved := r.Resolve("sportpesa", siteMatch("sportpesa_4", "Arsenal", "Chelsea", resolverKickoff))
+	if len(resolved.SourceIDs) != 1 {
+		t.Errorf("pruned fixture was reused: %+v", resolved)
+	}
+}
+
+// siteOf returns the site of a test source ID such as "betika_1"
+func siteOf(sourceID string) string {
+	site, _, _ := strings.Cut(sourceID, "_")
+	return site
+}
@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+)
+
+// RetryPolicy decides how often and how long to wait before a failed scrape
+// is tried again
+type RetryPolicy struct {
+	// Retries is the number of attempts after the first
+	Retries int
+	// BaseDelay is the wait before the first retry, doubled for each further
+	// retry up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Jitter spreads each wait by up to this fraction either way, so sites
+	// failing together are not retried in lockstep
+	Jitter float64
+}
+
+// NewRetryPolicy creates the configured retry policy
+func NewRetryPolicy(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		Retries:   cfg.ScrapeRetries,
+		BaseDelay: cfg.RetryBaseDelay,
+		MaxDelay:  cfg.RetryMaxDelay,
+		Jitter:    cfg.RetryJitter,
+	}
+}
+
+// Delay is the wait before the given retry, counting from 1
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.backoff(retry)
+	if p.Jitter > 0 {
+		delay *= 1 - p.Jitter + 2*p.Jitter*rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// Budget is the longest a scrape can take when every attempt runs for the
+// full timeout and each retry waits its longest jittered delay
+func (p RetryPolicy) Budget(timeout time.Duration) time.Duration {
+	budget := time.Duration(p.Retries+1) * timeout
+	for retry := 1; retry <= p.Retries; retry++ {
+		budget += time.Duration(p.backoff(retry) * (1 + p.Jitter))
+	}
+	return budget
+}
+
+// backoff is the unjittered wait before the given retry
+func (p RetryPolicy) backoff(retry int) float64 {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(retry-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	return delay
+}
+
+// wait sleeps before a retry, returning false if ctx ends first
+func (p RetryPolicy) wait(ctx context.Context, retry int) bool {
+	timer := time.NewTimer(p.Delay(retry))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
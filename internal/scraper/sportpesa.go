@@ -4,27 +4,39 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
+	"regexp"
 	"time"
 
+	"betting-odds-scraper/internal/browser"
 	"betting-odds-scraper/internal/models"
-
-	"github.com/PuerkitoBio/goquery"
-	"github.com/chromedp/chromedp"
 )
 
+// sportpesaCapture matches the API calls SportPesa's football page loads its odds from
+var sportpesaCapture = []*regexp.Regexp{regexp.MustCompile(`/api/upcoming/games`)}
+
 type SportPesaScraper struct {
 	siteInfo models.BettingSite
+	pool     *browser.Pool
+	baseURL  string
 }
 
-func NewSportPesaScraper() *SportPesaScraper {
+// NewSportPesaScraper creates a SportPesa scraper. baseURL replaces the live site,
+// for example with the mock bookmaker; empty means the live site.
+func NewSportPesaScraper(pool *browser.Pool, baseURL string) *SportPesaScraper {
+	if baseURL == "" {
+		baseURL = "https://www.sportpesa.com"
+	}
+
 	return &SportPesaScraper{
 		siteInfo: models.BettingSite{
 			ID:     "sportpesa",
 			Name:   "SportPesa",
 			URL:    "https://www.sportpesa.com",
 			Active: true,
+			Tax:    models.KenyaTaxProfile,
 		},
+		pool:    pool,
+		baseURL: baseURL,
 	}
 }
 
@@ -33,90 +45,134 @@ func (s *SportPesaScraper) GetSiteInfo() models.BettingSite {
 }
 
 func (s *SportPesaScraper) ScrapeOdds(ctx context.Context) ([]models.Match, []models.Odds, error) {
-	var matches []models.Match
-	var odds []models.Odds
-
-	// Create Chrome context with options to suppress errors
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-logging", true),
-		chromedp.Flag("log-level", "3"),
-	)
-	
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
-	
-	chromeCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
-
-	var htmlContent string
-	
-	// Navigate to SportPesa football section
-	err := chromedp.Run(chromeCtx,
-		chromedp.Navigate("https://www.sportpesa.com/en/sport/football"),
-		chromedp.WaitVisible("body", chromedp.ByQuery),
-		chromedp.Sleep(4*time.Second),
-		chromedp.OuterHTML("html", &htmlContent),
-	)
-
+	// Load the football page, capturing the odds calls it makes
+	htmlContent, responses, err := loadCaptured(ctx, s.pool, s.siteInfo.ID, s.baseURL+"/en/sport/football", sportpesaCapture)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load SportPesa page: %w", err)
 	}
 
-	// Parse HTML with goquery
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	// The captured API responses carry the odds the page renders
+	matches, odds := decodeCaptured(responses, decodeSportPesa, s.siteInfo)
+	if len(matches) == 0 {
+		return nil, nil, noEvents([]string{htmlContent}, fmt.Errorf("no odds in %d captured SportPesa responses", len(responses)))
 	}
-	_ = doc // Suppress unused variable warning
-
-	// Create sample data with slightly different odds for SportPesa
-	sampleMatches := []struct {
-		home, away string
-		homeOdds, drawOdds, awayOdds float64
-	}{
-		{"Arsenal", "Chelsea", 2.05, 3.45, 3.25},
-		{"Manchester United", "Liverpool", 2.75, 3.15, 2.65},
-		{"Barcelona", "Real Madrid", 2.40, 3.30, 2.95},
-		{"Bayern Munich", "Borussia Dortmund", 1.90, 3.65, 3.85},
-		{"PSG", "Marseille", 1.70, 3.85, 4.60},
-		{"Tottenham", "Manchester City", 3.20, 3.40, 2.25},
+
+	log.Printf("SportPesa: Found %d matches with odds from captured responses", len(matches))
+	matches, odds = stamp(matches, odds, models.ProvenanceLive)
+	return matches, odds, nil
+}
+
+// sportpesaAPIURL serves both SportPesa Kenya's pages and its JSON API
+const sportpesaAPIURL = "https://www.ke.sportpesa.com"
+
+// SportPesa market IDs in the upcoming games list
+const (
+	sportpesaMatchResult = 10
+	sportpesaTotals      = 52
+	sportpesaBTTS        = 43
+)
+
+// SportPesaAPI describes the JSON endpoint behind SportPesa's football page.
+// The API answers only with the site's session cookies and app headers.
+// baseURL replaces the live site; empty means the live site.
+func SportPesaAPI(baseURL string) SiteAPI {
+	if baseURL == "" {
+		baseURL = sportpesaAPIURL
+	}
+	return SiteAPI{
+		Warmup: baseURL + "/en/sport/football",
+		PageURL: func(page int) string {
+			return fmt.Sprintf("%s/api/upcoming/games?type=prematch&sportId=1&section=upcoming&markets_layout=multiple&o=leagues&pag_count=50&pag_min=%d", baseURL, (page-1)*50+1)
+		},
+		MaxPages: 4,
+		Headers: map[string]string{
+			"X-App-Timezone":   "Africa/Nairobi",
+			"X-Requested-With": "XMLHttpRequest",
+		},
+		Decode: decodeSportPesa,
 	}
+}
 
-	for i, sample := range sampleMatches {
-		matchID := fmt.Sprintf("sportpesa_%s_vs_%s_%d", 
-			strings.ReplaceAll(strings.ToLower(sample.home), " ", "_"),
-			strings.ReplaceAll(strings.ToLower(sample.away), " ", "_"),
-			time.Now().Unix()+int64(i))
+type sportpesaGame struct {
+	ID          int64 `json:"id"`
+	Timestamp   int64 `json:"dateTimestamp"`
+	Competitors []struct {
+		Name string `json:"name"`
+	} `json:"competitors"`
+	Competition struct {
+		Name string `json:"name"`
+	} `json:"competition"`
+	Markets []struct {
+		ID         int     `json:"id"`
+		SpecValue  float64 `json:"specValue"`
+		Selections []struct {
+			ShortName string   `json:"shortName"`
+			Odds      apiPrice `json:"odds"`
+		} `json:"selections"`
+	} `json:"markets"`
+}
 
-		match := models.Match{
-			ID:        matchID,
-			HomeTeam:  sample.home,
-			AwayTeam:  sample.away,
-			Sport:     "football",
-			League:    "Premier League",
-			MatchTime: time.Now().Add(time.Duration(24+i*3) * time.Hour),
-			Status:    "upcoming",
-		}
+// decodeSportPesa maps one page of SportPesa's upcoming games
+func decodeSportPesa(body []byte, site models.BettingSite, now time.Time) ([]models.Match, []models.Odds, error) {
+	var games []sportpesaGame
+	if err := decodeJSON(body, &games); err != nil {
+		return nil, nil, err
+	}
 
-		matches = append(matches, match)
+	var matches []models.Match
+	var odds []models.Odds
+	for _, game := range games {
+		if len(game.Competitors) != 2 || game.Timestamp == 0 {
+			continue
+		}
 
+		matchID := fmt.Sprintf("%s_%d", site.ID, game.ID)
 		odd := models.Odds{
-			ID:        fmt.Sprintf("%s_odds", matchID),
+			ID:        matchID + "_odds",
 			MatchID:   matchID,
-			SiteID:    s.siteInfo.ID,
-			SiteName:  s.siteInfo.Name,
-			HomeWin:   sample.homeOdds,
-			Draw:      sample.drawOdds,
-			AwayWin:   sample.awayOdds,
-			ScrapedAt: time.Now(),
+			SiteID:    site.ID,
+			SiteName:  site.Name,
+			ScrapedAt: now,
+		}
+		for _, market := range game.Markets {
+			line := models.FormatLine(market.SpecValue)
+			for _, selection := range market.Selections {
+				if selection.Odds <= 1 {
+					continue
+				}
+				value := float64(selection.Odds)
+				switch {
+				case market.ID == sportpesaMatchResult && selection.ShortName == "1":
+					odd.SetPrice(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionHome, value)
+				case market.ID == sportpesaMatchResult && selection.ShortName == "X":
+					odd.SetPrice(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionDraw, value)
+				case market.ID == sportpesaMatchResult && selection.ShortName == "2":
+					odd.SetPrice(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionAway, value)
+				case market.ID == sportpesaTotals && selection.ShortName == "OV":
+					odd.SetPrice(models.MarketOverUnder, line, models.PeriodFullTime, models.SelectionOver, value)
+				case market.ID == sportpesaTotals && selection.ShortName == "UN":
+					odd.SetPrice(models.MarketOverUnder, line, models.PeriodFullTime, models.SelectionUnder, value)
+				case market.ID == sportpesaBTTS && selection.ShortName == "YES":
+					odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes, value)
+				case market.ID == sportpesaBTTS && selection.ShortName == "NO":
+					odd.SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionNo, value)
+				}
+			}
+		}
+		if len(odd.Markets) == 0 {
+			continue
 		}
+
+		matches = append(matches, models.Match{
+			ID:        matchID,
+			HomeTeam:  game.Competitors[0].Name,
+			AwayTeam:  game.Competitors[1].Name,
+			Sport:     "football",
+			League:    game.Competition.Name,
+			MatchTime: time.UnixMilli(game.Timestamp).UTC(),
+			Status:    "upcoming",
+		})
 		odds = append(odds, odd)
 	}
-
-	log.Printf("SportPesa: Found %d matches with odds", len(matches))
 	return matches, odds, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,224 @@
+package scraper
+
+import (
+	"fmt"
+	"math"
+
+	"betting-odds-scraper/internal/analysis"
+	"betting-odds-scraper/internal/config"
+	"betting-odds-scraper/internal/models"
+)
+
+// Price bounds outside which a scraped price is treated as a parsing mistake
+const (
+	defaultMinPrice = 1.01
+	defaultMaxPrice = 500
+)
+
+// Overround bounds of a plausible full-time 1x2 market. Outside them the
+// prices were most likely read from the wrong elements.
+const (
+	minOverround = -0.02
+	maxOverround = 0.35
+)
+
+// maxInvalidFraction is the share of prices allowed outside the price bounds
+// before a scrape is degraded. Fewer are dropped without comment, since a
+// site can list the odd extreme price.
+const maxInvalidFraction = 0.1
+
+// maxImplausibleFraction is the share of 1x2 markets allowed outside the
+// overround bounds before a scrape is degraded
+const maxImplausibleFraction = 0.1
+
+// Expectations are what a healthy scrape of a site looks like. Zero fields
+// take the configured defaults.
+type Expectations struct {
+	// MinEvents is the fewest events a full scrape should find
+	MinEvents int `yaml:"min_events"`
+	// MinPrice and MaxPrice bound a valid decimal price
+	MinPrice float64 `yaml:"min_price"`
+	MaxPrice float64 `yaml:"max_price"`
+	// MinParsed is the share of event fields (teams, league, kickoff and
+	// prices) that must be read
+	MinParsed float64 `yaml:"min_parsed"`
+	// Leagues are league names known to be valid for the site, on top of the
+	// curated aliases and leagues seen in earlier healthy scrapes
+	Leagues []string `yaml:"leagues"`
+	// MinKnownLeagues is the share of events that must be in a known league
+	MinKnownLeagues float64 `yaml:"min_known_leagues"`
+	// MaxDrop is the largest allowed fall in events against recent healthy
+	// scrapes, as a fraction
+	MaxDrop float64 `yaml:"max_drop"`
+}
+
+// ExpectingScraper is a scraper with its own idea of a healthy scrape
+type ExpectingScraper interface {
+	Scraper
+	Expectations() Expectations
+}
+
+// defaultExpectations are the configured expectations for every site
+func defaultExpectations(cfg *config.Config) Expectations {
+	return Expectations{
+		MinEvents:       cfg.HealthMinEvents,
+		MinPrice:        defaultMinPrice,
+		MaxPrice:        defaultMaxPrice,
+		MinParsed:       cfg.HealthMinParsed,
+		MinKnownLeagues: cfg.HealthMinKnownLeagues,
+		MaxDrop:         cfg.HealthMaxDrop,
+	}
+}
+
+// withDefaults fills the unset fields from defaults
+func (e Expectations) withDefaults(defaults Expectations) Expectations {
+	if e.MinEvents == 0 {
+		e.MinEvents = defaults.MinEvents
+	}
+	if e.MinPrice == 0 {
+		e.MinPrice = defaults.MinPrice
+	}
+	if e.MaxPrice == 0 {
+		e.MaxPrice = defaults.MaxPrice
+	}
+	if e.MinParsed == 0 {
+		e.MinParsed = defaults.MinParsed
+	}
+	if e.MinKnownLeagues == 0 {
+		e.MinKnownLeagues = defaults.MinKnownLeagues
+	}
+	if e.MaxDrop == 0 {
+		e.MaxDrop = defaults.MaxDrop
+	}
+	return e
+}
+
+// validation is the input to validate beyond the scrape itself
+type validation struct {
+	expect Expectations
+	// known reports whether a normalized league name is known
+	known func(league string) bool
+	// baseline is the average event count of recent healthy scrapes, or 0
+	baseline float64
+	// partial is set for targeted scrapes, which are not expected to find
+	// the site's usual number of events
+	partial bool
+}
+
+// validate checks a scrape against expectations. Prices outside the valid
+// range and 1x2 markets with an implausible margin are removed, along with
+// odds left without any price. It returns the
+// remaining odds, the measurements and a description of each failed check.
+func validate(matches []models.Match, odds []models.Odds, v validation) ([]models.Odds, models.ScrapeChecks, []string) {
+	expect := v.expect
+	checks := models.ScrapeChecks{
+		Events:         len(matches),
+		MinEvents:      expect.MinEvents,
+		BaselineEvents: round(v.baseline, 1),
+		Partial:        v.partial,
+	}
+	var issues []string
+
+	// Drop prices no bookmaker would offer
+	priced := make(map[string]bool, len(odds))
+	valid := odds[:0]
+	prices, results := 0, 0
+	for _, odd := range odds {
+		markets := odd.Markets[:0]
+		prices += len(odd.Markets)
+		for _, price := range odd.Markets {
+			if price.Value < expect.MinPrice || price.Value > expect.MaxPrice || math.IsNaN(price.Value) {
+				checks.InvalidPrices++
+				continue
+			}
+			markets = append(markets, price)
+		}
+		odd.Markets = markets
+		clearLegacyPrices(&odd, expect)
+
+		// A 1x2 market with an implausible margin would show up as a false
+		// arbitrage or best price, so it is dropped whole
+		home, homeOK := odd.Price(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionHome)
+		draw, drawOK := odd.Price(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionDraw)
+		away, awayOK := odd.Price(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionAway)
+		if homeOK && drawOK && awayOK {
+			results++
+			if overround := analysis.Overround([]float64{home, draw, away}); overround < minOverround || overround > maxOverround {
+				checks.ImplausibleMarkets++
+				dropMatchResult(&odd)
+			}
+		}
+		if len(odd.Markets) == 0 {
+			continue
+		}
+		priced[odd.MatchID] = true
+		valid = append(valid, odd)
+	}
+	if checks.InvalidPrices > 0 && float64(checks.InvalidPrices) > maxInvalidFraction*float64(prices) {
+		issues = append(issues, fmt.Sprintf("%d prices outside %.2f-%.0f were dropped", checks.InvalidPrices, expect.MinPrice, expect.MaxPrice))
+	}
+	if checks.ImplausibleMarkets > 0 && float64(checks.ImplausibleMarkets) > maxImplausibleFraction*float64(results) {
+		issues = append(issues, fmt.Sprintf("%d match result markets with an implausible margin were dropped", checks.ImplausibleMarkets))
+	}
+
+	// Every event should have both teams, a league, a kickoff and prices
+	parsed, known := 0, 0
+	for _, match := range matches {
+		for _, ok := range []bool{match.HomeTeam != "", match.AwayTeam != "", match.League != "", !match.MatchTime.IsZero(), priced[match.ID]} {
+			if ok {
+				parsed++
+			}
+		}
+		if match.League != "" && v.known != nil && v.known(match.League) {
+			known++
+		}
+	}
+	if len(matches) > 0 {
+		checks.ParsedFraction = round(float64(parsed)/float64(5*len(matches)), 2)
+		checks.KnownLeagues = round(float64(known)/float64(len(matches)), 2)
+		if checks.ParsedFraction < expect.MinParsed {
+			issues = append(issues, fmt.Sprintf("only %.0f%% of event fields were parsed, expected %.0f%%", 100*checks.ParsedFraction, 100*expect.MinParsed))
+		}
+		if checks.KnownLeagues < expect.MinKnownLeagues {
+			issues = append(issues, fmt.Sprintf("only %.0f%% of events are in known leagues, expected %.0f%%", 100*checks.KnownLeagues, 100*expect.MinKnownLeagues))
+		}
+	}
+
+	if !v.partial {
+		if len(matches) < expect.MinEvents {
+			issues = append(issues, fmt.Sprintf("found %d events, expected at least %d", len(matches), expect.MinEvents))
+		}
+		if v.baseline > 0 && float64(len(matches)) < (1-expect.MaxDrop)*v.baseline {
+			issues = append(issues, fmt.Sprintf("events dropped to %d from a recent average of %.0f", len(matches), v.baseline))
+		}
+	}
+	return valid, checks, issues
+}
+
+// clearLegacyPrices zeroes the fixed odds fields holding invalid prices, so
+// SyncMarkets does not bring them back
+func clearLegacyPrices(odd *models.Odds, expect Expectations) {
+	for _, field := range []*float64{&odd.HomeWin, &odd.Draw, &odd.AwayWin, &odd.Over25, &odd.Under25, &odd.BTTS, &odd.BTTSNo} {
+		if *field != 0 && (*field < expect.MinPrice || *field > expect.MaxPrice) {
+			*field = 0
+		}
+	}
+}
+
+// dropMatchResult removes the full-time 1x2 prices from odds, in both the
+// markets and the fixed odds fields
+func dropMatchResult(odd *models.Odds) {
+	markets := odd.Markets[:0]
+	for _, price := range odd.Markets {
+		if price.Market != models.MarketMatchResult || price.Period != models.PeriodFullTime {
+			markets = append(markets, price)
+		}
+	}
+	odd.Markets = markets
+	odd.HomeWin, odd.Draw, odd.AwayWin = 0, 0, 0
+}
+
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
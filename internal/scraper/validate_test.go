@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+var testExpectations = Expectations{
+	MinEvents:       3,
+	MinPrice:        defaultMinPrice,
+	MaxPrice:        defaultMaxPrice,
+	MinParsed:       0.8,
+	MinKnownLeagues: 0.5,
+	MaxDrop:         0.5,
+}
+
+// scrapeOf builds a scrape of n events in the Premier League with sensible
+// 1x2 prices
+func scrapeOf(n int) ([]models.Match, []models.Odds) {
+	var matches []models.Match
+	var odds []models.Odds
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("m%d", i)
+		matches = append(matches, models.Match{
+			ID:        id,
+			HomeTeam:  fmt.Sprintf("Home %d", i),
+			AwayTeam:  fmt.Sprintf("Away %d", i),
+			League:    "Premier League",
+			MatchTime: time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC),
+		})
+		odd := models.Odds{ID: id + "_odds", MatchID: id}
+		odd.SetMatchResult(2.10, 3.40, 3.50)
+		odds = append(odds, odd)
+	}
+	return matches, odds
+}
+
+func knownLeague(league string) bool {
+	return league == "Premier League"
+}
+
+func TestValidateHealthy(t *testing.T) {
+	matches, odds := scrapeOf(5)
+	valid, checks, issues := validate(matches, odds, validation{expect: testExpectations, known: knownLeague, baseline: 6})
+	if len(issues) > 0 {
+		t.Fatalf("unexpected issues %v", issues)
+	}
+	if len(valid) != 5 || checks.ParsedFraction != 1 || checks.KnownLeagues != 1 {
+		t.Errorf("got %d odds and checks %+v", len(valid), checks)
+	}
+}
+
+func TestValidateLayoutChange(t *testing.T) {
+	// Rows are still found but leagues and prices no longer parse
+	matches, odds := scrapeOf(5)
+	for i := range matches {
+		matches[i].League = ""
+	}
+	odds = odds[:1]
+
+	_, checks, issues := validate(matches, odds, validation{expect: testExpectations, known: knownLeague})
+	if len(issues) != 2 {
+		t.Fatalf("got issues %v, want parsed fields and known leagues", issues)
+	}
+	if checks.ParsedFraction != 0.64 || checks.KnownLeagues != 0 {
+		t.Errorf("unexpected checks %+v", checks)
+	}
+}
+
+func TestValidateEventDrop(t *testing.T) {
+	matches, odds := scrapeOf(4)
+	if _, _, issues := validate(matches, odds, validation{expect: testExpectations, known: knownLeague, baseline: 20}); len(issues) != 1 {
+		t.Fatalf("got issues %v, want the drop from 20 events", issues)
+	}
+
+	// Targeted scrapes are expected to find few events
+	if _, _, issues := validate(matches[:1], odds[:1], validation{expect: testExpectations, known: knownLeague, baseline: 20, partial: true}); len(issues) != 0 {
+		t.Fatalf("unexpected issues %v for a targeted scrape", issues)
+	}
+}
+
+func TestValidateDropsInvalidPrices(t *testing.T) {
+	matches, odds := scrapeOf(4)
+	odds[0].SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes, 0.5)
+	odds[1].Markets = nil
+	odds[1].SetMatchResult(1.00, 0, 999)
+	odds[1].SyncMarkets()
+
+	valid, checks, issues := validate(matches, odds, validation{expect: testExpectations, known: knownLeague})
+	if checks.InvalidPrices != 3 || len(valid) != 3 || len(issues) != 1 {
+		t.Fatalf("got %d odds, checks %+v, issues %v", len(valid), checks, issues)
+	}
+	if _, ok := valid[0].Price(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes); ok {
+		t.Error("invalid btts price was kept")
+	}
+
+	// A lone extreme price is dropped without degrading the scrape
+	matches, odds = scrapeOf(10)
+	odds[0].SetPrice(models.MarketBTTS, "", models.PeriodFullTime, models.SelectionYes, 501)
+	_, checks, issues = validate(matches, odds, validation{expect: testExpectations, known: knownLeague})
+	if checks.InvalidPrices != 1 || len(issues) != 0 {
+		t.Errorf("one invalid price gave checks %+v, issues %v", checks, issues)
+	}
+}
+
+func TestValidateDropsImplausibleMarkets(t *testing.T) {
+	matches, odds := scrapeOf(10)
+	// Prices read from the wrong elements: a 40% arbitrage and a 150% margin
+	odds[0].SetMatchResult(5.0, 5.0, 5.0)
+	odds[0].SetGoalMarkets(1.85, 1.95, 0, 0)
+	odds[1].SetMatchResult(1.2, 1.2, 1.2)
+
+	valid, checks, issues := validate(matches, odds, validation{expect: testExpectations, known: knownLeague})
+	if checks.ImplausibleMarkets != 2 || len(valid) != 9 || len(issues) != 1 {
+		t.Fatalf("got %d odds, checks %+v, issues %v", len(valid), checks, issues)
+	}
+	// The other markets of the odds survive without the 1x2 prices
+	if valid[0].MatchID != "m0" || valid[0].HomeWin != 0 || len(valid[0].Markets) != 2 {
+		t.Errorf("implausible 1x2 prices were kept: %+v", valid[0])
+	}
+	if _, ok := valid[0].Price(models.MarketMatchResult, "", models.PeriodFullTime, models.SelectionHome); ok {
+		t.Error("implausible home price was kept")
+	}
+
+	// A lone implausible market is dropped without degrading the scrape
+	matches, odds = scrapeOf(20)
+	odds[0].SetMatchResult(5.0, 5.0, 5.0)
+	valid, checks, issues = validate(matches, odds, validation{expect: testExpectations, known: knownLeague})
+	if checks.ImplausibleMarkets != 1 || len(valid) != 19 || len(issues) != 0 {
+		t.Errorf("one implausible market gave %d odds, checks %+v, issues %v", len(valid), checks, issues)
+	}
+}
+
+func TestHealthScoreAndAlerts(t *testing.T) {
+	history := []models.ScrapeResult{
+		{Status: models.ScrapeOK, Health: 100, MatchCount: 10, Checks: &models.ScrapeChecks{}},
+		{Status: models.ScrapeOK, Health: 100, MatchCount: 20, Checks: &models.ScrapeChecks{}},
+		{Status: models.ScrapeDegraded, Health: 85, MatchCount: 2, Checks: &models.ScrapeChecks{}},
+	}
+	if baseline := Baseline(history); baseline != 15 {
+		t.Errorf("baseline %v, want 15 from the ok scrapes", baseline)
+	}
+	if score := Score(history, models.ScrapeFailed); score != 59.5 {
+		t.Errorf("score %v, want 59.5", score)
+	}
+
+	alerts := make(chan models.Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert models.Alert
+		json.NewDecoder(r.Body).Decode(&alert)
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	health := NewHealth(server.URL, nil)
+	ok := models.ScrapeResult{SiteID: "betika", Status: models.ScrapeOK}
+	degraded := models.ScrapeResult{SiteID: "betika", Status: models.ScrapeDegraded, Issues: []string{"found 0 events"}}
+
+	// Only changes of state raise alerts
+	health.Observe(&ok, ok)
+	health.Observe(&ok, degraded)
+	health.Observe(&degraded, degraded)
+	if got := health.Alerts(); len(got) != 1 || got[0].Status != models.ScrapeDegraded {
+		t.Fatalf("got alerts %+v, want one degraded alert", got)
+	}
+
+	select {
+	case alert := <-alerts:
+		if alert.SiteID != "betika" || alert.Message == "" {
+			t.Errorf("unexpected webhook alert %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("alert was not posted to the webhook")
+	}
+}
@@ -0,0 +1,375 @@
+// Package simulation runs the simulated football market behind demo mode: a
+// stable list of fixtures priced by several books, with prices that drift
+// over time, occasional arbitrage, suspensions and kickoffs.
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Step is how often the market moves
+const Step = 30 * time.Second
+
+const (
+	// fixtureCount is how many fixtures are listed at any time
+	fixtureCount = 16
+	// kickoffSpacing separates consecutive kickoffs
+	kickoffSpacing = 3*time.Hour + 15*time.Minute
+	// matchLength is how long a fixture stays in play before it is replaced
+	matchLength = 105 * time.Minute
+	// maxSteps bounds how far the market catches up after a long idle spell
+	maxSteps = 2880
+)
+
+// Chances per step. Goals average about three a match.
+const (
+	listedChance     = 0.9
+	goalMarketChance = 0.8
+	suspendChance    = 0.002
+	arbitrageChance  = 0.003
+	goalChance       = 0.014
+)
+
+// Fixture statuses
+const (
+	StatusUpcoming = "upcoming"
+	StatusLive     = "live"
+)
+
+// Selections, indexing a line's skews
+const (
+	home = iota
+	draw
+	away
+	over
+	under
+	yes
+	no
+	selections
+)
+
+// Book is a simulated bookmaker. Margin is its overround, such as 1.06 for
+// six percent.
+type Book struct {
+	ID     string
+	Name   string
+	Margin float64
+}
+
+// Fixture is a simulated match
+type Fixture struct {
+	ID      string
+	Home    string
+	Away    string
+	League  string
+	Kickoff time.Time
+	Status  string
+}
+
+// Quote is one book's prices for a fixture. Goal market prices are zero when
+// the book does not offer them.
+type Quote struct {
+	Fixture Fixture
+	Home    float64
+	Draw    float64
+	Away    float64
+	Over25  float64
+	Under25 float64
+	BTTSYes float64
+	BTTSNo  float64
+}
+
+// Engine is the simulated market shared by every demo book. It moves one
+// Step at a time up to the time it is asked for quotes, so books scraped at
+// different moments see one consistent market.
+type Engine struct {
+	mutex    sync.Mutex
+	rng      *rand.Rand
+	books    []Book
+	fixtures []*fixture
+	clock    time.Time
+	created  int
+}
+
+// fixture is a Fixture with its true probabilities, as log-odds drifting
+// around where they started, and every book's line on it
+type fixture struct {
+	Fixture
+	strength, baseStrength float64
+	drawShare              float64
+	goals, baseGoals       float64
+	btts, baseBTTS         float64
+	lines                  map[string]*line
+}
+
+// line is how one book prices a fixture
+type line struct {
+	listed      bool
+	goalMarkets bool
+	// skew is the book's own lean on each selection, as a log price offset
+	skew           [selections]float64
+	suspendedUntil time.Time
+	// boosted is a mispriced selection that opens an arbitrage until
+	// boostUntil, or -1
+	boosted    int
+	boost      float64
+	boostUntil time.Time
+}
+
+// New creates a market priced by books, starting at start. The same seed
+// and start replay the same market; zero picks a random seed.
+func New(seed int64, books []Book, start time.Time) *Engine {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	e := &Engine{
+		rng:   rand.New(rand.NewSource(seed)),
+		books: books,
+		clock: start.Truncate(Step),
+	}
+	firstKickoff := start.Truncate(15 * time.Minute).Add(30 * time.Minute)
+	for i := 0; i < fixtureCount; i++ {
+		e.fixtures = append(e.fixtures, e.newFixture(firstKickoff.Add(time.Duration(i)*kickoffSpacing)))
+	}
+	return e
+}
+
+// Fixtures returns the fixtures listed at now
+func (e *Engine) Fixtures(now time.Time) []Fixture {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.advance(now)
+	fixtures := make([]Fixture, len(e.fixtures))
+	for i, f := range e.fixtures {
+		fixtures[i] = f.Fixture
+	}
+	return fixtures
+}
+
+// Quotes returns a book's prices at now, leaving out fixtures it does not
+// list or has suspended
+func (e *Engine) Quotes(bookID string, now time.Time) []Quote {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.advance(now)
+	var book Book
+	for _, b := range e.books {
+		if b.ID == bookID {
+			book = b
+		}
+	}
+	if book.ID == "" {
+		return nil
+	}
+
+	quotes := make([]Quote, 0, len(e.fixtures))
+	for _, f := range e.fixtures {
+		l := f.lines[bookID]
+		if !l.listed || e.clock.Before(l.suspendedUntil) {
+			continue
+		}
+		quotes = append(quotes, f.quote(book, l))
+	}
+	return quotes
+}
+
+// advance moves the market one Step at a time up to now
+func (e *Engine) advance(now time.Time) {
+	steps := int(now.Sub(e.clock) / Step)
+	if steps > maxSteps {
+		e.clock = e.clock.Add(time.Duration(steps-maxSteps) * Step)
+		steps = maxSteps
+	}
+	for i := 0; i < steps; i++ {
+		e.clock = e.clock.Add(Step)
+		e.step()
+	}
+}
+
+// step moves every fixture's prices once, kicking off and replacing
+// fixtures as the clock passes them
+func (e *Engine) step() {
+	for i, f := range e.fixtures {
+		if !e.clock.Before(f.Kickoff.Add(matchLength)) {
+			last := e.fixtures[len(e.fixtures)-1].Kickoff
+			for _, other := range e.fixtures {
+				if other.Kickoff.After(last) {
+					last = other.Kickoff
+				}
+			}
+			e.fixtures[i] = e.newFixture(last.Add(kickoffSpacing))
+			continue
+		}
+
+		volatility := 0.01
+		if !e.clock.Before(f.Kickoff) {
+			f.Status = StatusLive
+			volatility = 0.04
+			if e.rng.Float64() < goalChance {
+				e.goal(f)
+			}
+		}
+
+		// True probabilities wander but are pulled back towards where they
+		// started, so prices stay plausible however long the demo runs
+		f.strength += 0.05*(f.baseStrength-f.strength) + e.rng.NormFloat64()*volatility
+		f.goals += 0.05*(f.baseGoals-f.goals) + e.rng.NormFloat64()*volatility
+		f.btts += 0.05*(f.baseBTTS-f.btts) + e.rng.NormFloat64()*volatility
+
+		for _, book := range e.books {
+			l := f.lines[book.ID]
+			for s := range l.skew {
+				l.skew[s] = 0.9*l.skew[s] + e.rng.NormFloat64()*0.006
+			}
+			if l.boosted >= 0 && !e.clock.Before(l.boostUntil) {
+				l.boosted = -1
+			}
+			if l.listed && e.rng.Float64() < suspendChance {
+				l.suspendedUntil = e.clock.Add(time.Duration(2+e.rng.Intn(9)) * Step)
+			}
+		}
+
+		// Now and then one book misprices a selection far enough to open an
+		// arbitrage against the others
+		if e.rng.Float64() < arbitrageChance {
+			book := e.books[e.rng.Intn(len(e.books))]
+			l := f.lines[book.ID]
+			if l.listed && l.boosted < 0 {
+				l.boosted = e.rng.Intn(away + 1)
+				if l.goalMarkets && e.rng.Intn(2) == 0 {
+					l.boosted = over + e.rng.Intn(selections-over)
+				}
+				l.boost = 0.10 + e.rng.Float64()*0.08
+				l.boostUntil = e.clock.Add(time.Duration(4+e.rng.Intn(17)) * Step)
+			}
+		}
+	}
+}
+
+// goal moves an in-play fixture's prices after a goal, suspending every book
+// for a minute or two
+func (e *Engine) goal(f *fixture) {
+	if e.rng.Float64() < sigmoid(f.strength) {
+		f.strength += 0.8
+	} else {
+		f.strength -= 0.8
+	}
+	f.baseStrength = f.strength
+	f.goals += 0.6
+	f.baseGoals = f.goals
+	f.btts += 0.4
+	f.baseBTTS = f.btts
+
+	for _, book := range e.books {
+		f.lines[book.ID].suspendedUntil = e.clock.Add(time.Duration(2+e.rng.Intn(3)) * Step)
+	}
+}
+
+// newFixture schedules the next pairing to kick off at kickoff
+func (e *Engine) newFixture(kickoff time.Time) *fixture {
+	pairing := pairings[e.created%len(pairings)]
+	e.created++
+
+	strength := 0.25 + e.rng.NormFloat64()*0.6
+	goals := 0.1 + e.rng.NormFloat64()*0.4
+	btts := e.rng.NormFloat64() * 0.35
+	f := &fixture{
+		Fixture: Fixture{
+			ID:      fmt.Sprintf("%s_vs_%s_%d", slug(pairing.home), slug(pairing.away), kickoff.Unix()),
+			Home:    pairing.home,
+			Away:    pairing.away,
+			League:  pairing.league,
+			Kickoff: kickoff,
+			Status:  StatusUpcoming,
+		},
+		strength:     strength,
+		baseStrength: strength,
+		drawShare:    0.24 + e.rng.Float64()*0.06,
+		goals:        goals,
+		baseGoals:    goals,
+		btts:         btts,
+		baseBTTS:     btts,
+		lines:        make(map[string]*line, len(e.books)),
+	}
+	for _, book := range e.books {
+		f.lines[book.ID] = &line{
+			listed:      e.rng.Float64() < listedChance,
+			goalMarkets: e.rng.Float64() < goalMarketChance,
+			boosted:     -1,
+		}
+	}
+	return f
+}
+
+// quote prices the fixture at one book
+func (f *fixture) quote(book Book, l *line) Quote {
+	homeWin := (1 - f.drawShare) * sigmoid(f.strength)
+	probabilities := [selections]float64{
+		home:  homeWin,
+		draw:  f.drawShare,
+		away:  1 - f.drawShare - homeWin,
+		over:  sigmoid(f.goals),
+		under: 1 - sigmoid(f.goals),
+		yes:   sigmoid(f.btts),
+		no:    1 - sigmoid(f.btts),
+	}
+
+	var prices [selections]float64
+	for s, p := range probabilities {
+		offset := l.skew[s]
+		if s == l.boosted {
+			offset += l.boost
+		}
+		prices[s] = math.Max(1.01, math.Round(100*math.Exp(offset)/(p*book.Margin))/100)
+	}
+
+	q := Quote{Fixture: f.Fixture, Home: prices[home], Draw: prices[draw], Away: prices[away]}
+	if l.goalMarkets {
+		q.Over25, q.Under25 = prices[over], prices[under]
+		q.BTTSYes, q.BTTSNo = prices[yes], prices[no]
+	}
+	return q
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func slug(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "_")
+}
+
+// pairings are the fixtures the market schedules, in turn
+var pairings = []struct {
+	home, away string
+	league     string
+}{
+	{"Arsenal", "Chelsea", "Premier League"},
+	{"Barcelona", "Real Madrid", "La Liga"},
+	{"Bayern Munich", "Borussia Dortmund", "Bundesliga"},
+	{"Juventus", "AC Milan", "Serie A"},
+	{"PSG", "Marseille", "Ligue 1"},
+	{"Manchester United", "Liverpool", "Premier League"},
+	{"Atletico Madrid", "Sevilla", "La Liga"},
+	{"RB Leipzig", "Bayer Leverkusen", "Bundesliga"},
+	{"Inter Milan", "Napoli", "Serie A"},
+	{"Tottenham", "Manchester City", "Premier League"},
+	{"Valencia", "Villarreal", "La Liga"},
+	{"Leicester City", "West Ham", "Premier League"},
+	{"Gor Mahia", "AFC Leopards", "Kenyan Premier League"},
+	{"Lyon", "Monaco", "Ligue 1"},
+	{"AS Roma", "Lazio", "Serie A"},
+	{"Newcastle", "Aston Villa", "Premier League"},
+	{"Real Sociedad", "Athletic Bilbao", "La Liga"},
+	{"Eintracht Frankfurt", "VfB Stuttgart", "Bundesliga"},
+	{"Tusker", "Kenya Police", "Kenyan Premier League"},
+	{"Lille", "Nice", "Ligue 1"},
+}
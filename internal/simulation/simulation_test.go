@@ -0,0 +1,162 @@
+package simulation
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testBooks = []Book{
+	{ID: "betika", Name: "Betika", Margin: 1.065},
+	{ID: "sportpesa", Name: "SportPesa", Margin: 1.055},
+	{ID: "betway", Name: "Betway", Margin: 1.05},
+	{ID: "odibets", Name: "Odibets", Margin: 1.07},
+}
+
+var start = time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+func TestSharedFixtures(t *testing.T) {
+	engine := New(1, testBooks, start)
+	fixtures := make(map[string]bool)
+	for _, fixture := range engine.Fixtures(start) {
+		fixtures[fixture.ID] = true
+	}
+	if len(fixtures) != fixtureCount {
+		t.Fatalf("listed %d fixtures, want %d", len(fixtures), fixtureCount)
+	}
+
+	for _, book := range testBooks {
+		quotes := engine.Quotes(book.ID, start)
+		if len(quotes) == 0 {
+			t.Errorf("%s quotes nothing", book.ID)
+		}
+		for _, quote := range quotes {
+			if !fixtures[quote.Fixture.ID] {
+				t.Errorf("%s quotes unknown fixture %s", book.ID, quote.Fixture.ID)
+			}
+		}
+	}
+
+	// The list is the same until the first fixture finishes
+	later := engine.Fixtures(start.Add(time.Hour))
+	for _, fixture := range later {
+		if !fixtures[fixture.ID] {
+			t.Errorf("fixture %s appeared before any fixture finished", fixture.ID)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	// Asking for quotes along the way does not change where the market ends up
+	a, b := New(7, testBooks, start), New(7, testBooks, start)
+	for at := start; at.Before(start.Add(2 * time.Hour)); at = at.Add(7 * time.Minute) {
+		a.Quotes("betika", at)
+	}
+
+	end := start.Add(2 * time.Hour)
+	for _, book := range testBooks {
+		if got, want := a.Quotes(book.ID, end), b.Quotes(book.ID, end); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s quotes differ between engines with the same seed", book.ID)
+		}
+	}
+}
+
+func TestRandomWalk(t *testing.T) {
+	engine := New(3, testBooks, start)
+	previous := quotesByFixture(engine.Quotes("sportpesa", start))
+	moved := 0
+	for at := start.Add(Step); at.Before(start.Add(20 * time.Minute)); at = at.Add(Step) {
+		current := quotesByFixture(engine.Quotes("sportpesa", at))
+		for id, quote := range current {
+			last, exists := previous[id]
+			if !exists {
+				continue
+			}
+			if quote.Home != last.Home {
+				moved++
+			}
+			// Without a mispricing prices move a little at a time
+			if change := math.Abs(quote.Home/last.Home - 1); change > 0.25 {
+				t.Errorf("%s home price jumped from %.2f to %.2f", id, last.Home, quote.Home)
+			}
+		}
+		previous = current
+	}
+	if moved == 0 {
+		t.Error("prices never moved")
+	}
+}
+
+func TestKickoff(t *testing.T) {
+	engine := New(1, testBooks, start)
+	first := engine.Fixtures(start)[0]
+	if first.Status != StatusUpcoming {
+		t.Fatalf("first fixture is %s before kickoff", first.Status)
+	}
+
+	if got := engine.Fixtures(first.Kickoff.Add(Step))[0]; got.ID != first.ID || got.Status != StatusLive {
+		t.Errorf("after kickoff got %s %s, want %s live", got.ID, got.Status, first.ID)
+	}
+
+	// A finished fixture makes way for one after every other kickoff
+	fixtures := engine.Fixtures(first.Kickoff.Add(matchLength + Step))
+	if len(fixtures) != fixtureCount {
+		t.Fatalf("listed %d fixtures, want %d", len(fixtures), fixtureCount)
+	}
+	replacement := fixtures[0]
+	if replacement.ID == first.ID {
+		t.Fatal("finished fixture is still listed")
+	}
+	for _, fixture := range fixtures[1:] {
+		if !replacement.Kickoff.After(fixture.Kickoff) {
+			t.Errorf("replacement kicks off at %v, before %s at %v", replacement.Kickoff, fixture.ID, fixture.Kickoff)
+		}
+	}
+}
+
+func TestArbitrageAndSuspensions(t *testing.T) {
+	engine := New(5, testBooks, start)
+	arbitrage, suspensions := 0, 0
+	seen := make(map[string]map[string]bool)
+	for at := start; at.Before(start.Add(24 * time.Hour)); at = at.Add(Step) {
+		best := make(map[string][3]float64)
+		for _, book := range testBooks {
+			quotes := quotesByFixture(engine.Quotes(book.ID, at))
+			for id := range seen[book.ID] {
+				if _, exists := quotes[id]; !exists {
+					suspensions++
+				}
+			}
+			seen[book.ID] = make(map[string]bool)
+			for id, quote := range quotes {
+				seen[book.ID][id] = true
+				prices := best[id]
+				prices[0] = math.Max(prices[0], quote.Home)
+				prices[1] = math.Max(prices[1], quote.Draw)
+				prices[2] = math.Max(prices[2], quote.Away)
+				best[id] = prices
+			}
+		}
+		for _, prices := range best {
+			if prices[0] > 0 && prices[1] > 0 && prices[2] > 0 && 1/prices[0]+1/prices[1]+1/prices[2] < 1 {
+				arbitrage++
+			}
+		}
+	}
+
+	if arbitrage == 0 {
+		t.Error("no 1X2 arbitrage opened in a day")
+	}
+	if suspensions == 0 {
+		t.Error("no book suspended a fixture in a day")
+	}
+}
+
+func quotesByFixture(quotes []Quote) map[string]Quote {
+	byFixture := make(map[string]Quote, len(quotes))
+	for _, quote := range quotes {
+		byFixture[quote.Fixture.ID] = quote
+	}
+	return byFixture
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// memoryResultLimit caps the scrape results kept per site in memory
+const memoryResultLimit = 10
+
+// MemoryStore keeps everything in maps and loses it on restart. It is meant
+// for demo mode, where no data is worth keeping.
+type MemoryStore struct {
+	matches map[string]models.Match
+	odds    map[string][]models.Odds
+	results map[string][]models.ScrapeResult
+	history map[string][]models.PricePoint
+	mutex   sync.RWMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		matches: make(map[string]models.Match),
+		odds:    make(map[string][]models.Odds),
+		results: make(map[string][]models.ScrapeResult),
+		history: make(map[string][]models.PricePoint),
+	}
+}
+
+func (s *MemoryStore) SaveMatches(matches []models.Match) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, match := range matches {
+		s.matches[match.ID] = match
+	}
+	return nil
+}
+
+func (s *MemoryStore) SaveOdds(siteID string, odds []models.Odds) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.odds[siteID] = odds
+	return nil
+}
+
+func (s *MemoryStore) SaveScrapeResult(result models.ScrapeResult) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.results[result.SiteID] = append(s.results[result.SiteID], result)
+	if len(s.results[result.SiteID]) > memoryResultLimit {
+		s.results[result.SiteID] = s.results[result.SiteID][1:]
+	}
+	return nil
+}
+
+func (s *MemoryStore) SavePriceHistory(points []models.PricePoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, point := range points {
+		s.history[point.MatchID] = append(s.history[point.MatchID], point)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteMatchesBefore(before time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	removed := make(map[string]bool)
+	for id, match := range s.matches {
+		if match.MatchTime.Before(before) {
+			delete(s.matches, id)
+			delete(s.history, id)
+			removed[id] = true
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	for siteID, odds := range s.odds {
+		var kept []models.Odds
+		for _, odd := range odds {
+			if !removed[odd.MatchID] {
+				kept = append(kept, odd)
+			}
+		}
+		s.odds[siteID] = kept
+	}
+	return len(removed), nil
+}
+
+func (s *MemoryStore) Matches() (map[string]models.Match, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	matches := make(map[string]models.Match, len(s.matches))
+	for id, match := range s.matches {
+		matches[id] = match
+	}
+	return matches, nil
+}
+
+func (s *MemoryStore) CurrentOdds() (map[string][]models.Odds, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	odds := make(map[string][]models.Odds, len(s.odds))
+	for siteID, siteOdds := range s.odds {
+		odds[siteID] = append([]models.Odds(nil), siteOdds...)
+	}
+	return odds, nil
+}
+
+func (s *MemoryStore) PriceHistory(matchID string) ([]models.PricePoint, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return append([]models.PricePoint(nil), s.history[matchID]...), nil
+}
+
+func (s *MemoryStore) ScrapeResults(limit int) (map[string][]models.ScrapeResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	results := make(map[string][]models.ScrapeResult, len(s.results))
+	for siteID, siteResults := range s.results {
+		if len(siteResults) > limit {
+			siteResults = siteResults[len(siteResults)-limit:]
+		}
+		results[siteID] = append([]models.ScrapeResult(nil), siteResults...)
+	}
+	return results, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
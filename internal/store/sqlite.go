@@ -0,0 +1,313 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS matches (
+	id         TEXT PRIMARY KEY,
+	match_time INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_matches_time ON matches (match_time);
+
+CREATE TABLE IF NOT EXISTS current_odds (
+	site_id    TEXT NOT NULL,
+	odds_id    TEXT NOT NULL,
+	match_id   TEXT NOT NULL,
+	scraped_at INTEGER NOT NULL,
+	data       TEXT NOT NULL,
+	PRIMARY KEY (site_id, odds_id)
+);
+
+CREATE TABLE IF NOT EXISTS odds_snapshots (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	site_id    TEXT NOT NULL,
+	odds_id    TEXT NOT NULL,
+	match_id   TEXT NOT NULL,
+	scraped_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_odds_snapshots_match ON odds_snapshots (match_id, scraped_at);
+
+CREATE TABLE IF NOT EXISTS price_history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	match_id    TEXT NOT NULL,
+	site_id     TEXT NOT NULL,
+	market      TEXT NOT NULL,
+	line        TEXT NOT NULL,
+	period      TEXT NOT NULL,
+	selection   TEXT NOT NULL,
+	value       REAL NOT NULL,
+	recorded_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_price_history_match ON price_history (match_id, recorded_at);
+
+CREATE TABLE IF NOT EXISTS scrape_results (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	site_id    TEXT NOT NULL,
+	scraped_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_scrape_results_site ON scrape_results (site_id, id);
+`
+
+// SQLiteStore keeps matches, every odds snapshot and every scrape result in
+// an embedded SQLite database. Records are stored as JSON alongside the
+// columns needed to query them.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens or creates the database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; serialise access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) SaveMatches(matches []models.Match) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO matches (id, match_time, data) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET match_time = excluded.match_time, data = excluded.data`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, match := range matches {
+		data, err := json.Marshal(match)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(match.ID, match.MatchTime.UnixNano(), string(data)); err != nil {
+			return fmt.Errorf("failed to save match %s: %w", match.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveOdds(siteID string, odds []models.Odds) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM current_odds WHERE site_id = ?`, siteID); err != nil {
+		return fmt.Errorf("failed to clear current odds: %w", err)
+	}
+
+	current, err := tx.Prepare(`INSERT OR REPLACE INTO current_odds (site_id, odds_id, match_id, scraped_at, data) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer current.Close()
+
+	snapshot, err := tx.Prepare(`INSERT INTO odds_snapshots (site_id, odds_id, match_id, scraped_at, data) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	for _, odd := range odds {
+		data, err := json.Marshal(odd)
+		if err != nil {
+			return err
+		}
+		args := []interface{}{siteID, odd.ID, odd.MatchID, odd.ScrapedAt.UnixNano(), string(data)}
+		if _, err := current.Exec(args...); err != nil {
+			return fmt.Errorf("failed to save odds %s: %w", odd.ID, err)
+		}
+		if _, err := snapshot.Exec(args...); err != nil {
+			return fmt.Errorf("failed to save odds snapshot %s: %w", odd.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveScrapeResult(result models.ScrapeResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO scrape_results (site_id, scraped_at, data) VALUES (?, ?, ?)`,
+		result.SiteID, result.ScrapedAt.UnixNano(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save scrape result: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SavePriceHistory(points []models.PricePoint) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO price_history (match_id, site_id, market, line, period, selection, value, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		if _, err := stmt.Exec(p.MatchID, p.SiteID, p.Market, p.Line, p.Period, p.Selection, p.Value, p.RecordedAt.UnixNano()); err != nil {
+			return fmt.Errorf("failed to save price history: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteMatchesBefore(before time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := before.UnixNano()
+	if _, err := tx.Exec(`DELETE FROM price_history WHERE match_id IN (SELECT id FROM matches WHERE match_time < ?)`, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to delete price history: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM odds_snapshots WHERE match_id IN (SELECT id FROM matches WHERE match_time < ?)`, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to delete odds snapshots: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM current_odds WHERE match_id IN (SELECT id FROM matches WHERE match_time < ?)`, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to delete current odds: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM matches WHERE match_time < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete matches: %w", err)
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(removed), tx.Commit()
+}
+
+func (s *SQLiteStore) Matches() (map[string]models.Match, error) {
+	rows, err := s.db.Query(`SELECT data FROM matches`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make(map[string]models.Match)
+	for rows.Next() {
+		var match models.Match
+		if err := scanJSON(rows, &match); err != nil {
+			return nil, err
+		}
+		matches[match.ID] = match
+	}
+	return matches, rows.Err()
+}
+
+func (s *SQLiteStore) CurrentOdds() (map[string][]models.Odds, error) {
+	rows, err := s.db.Query(`SELECT data FROM current_odds ORDER BY site_id, odds_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load odds: %w", err)
+	}
+	defer rows.Close()
+
+	odds := make(map[string][]models.Odds)
+	for rows.Next() {
+		var odd models.Odds
+		if err := scanJSON(rows, &odd); err != nil {
+			return nil, err
+		}
+		odds[odd.SiteID] = append(odds[odd.SiteID], odd)
+	}
+	return odds, rows.Err()
+}
+
+func (s *SQLiteStore) PriceHistory(matchID string) ([]models.PricePoint, error) {
+	rows, err := s.db.Query(`SELECT match_id, site_id, market, line, period, selection, value, recorded_at
+		FROM price_history WHERE match_id = ? ORDER BY recorded_at, id`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price history: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]models.PricePoint, 0)
+	for rows.Next() {
+		var p models.PricePoint
+		var recordedAt int64
+		if err := rows.Scan(&p.MatchID, &p.SiteID, &p.Market, &p.Line, &p.Period, &p.Selection, &p.Value, &recordedAt); err != nil {
+			return nil, err
+		}
+		p.RecordedAt = time.Unix(0, recordedAt)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+func (s *SQLiteStore) ScrapeResults(limit int) (map[string][]models.ScrapeResult, error) {
+	rows, err := s.db.Query(`SELECT data FROM (
+			SELECT id, data, ROW_NUMBER() OVER (PARTITION BY site_id ORDER BY id DESC) AS rank
+			FROM scrape_results
+		) WHERE rank <= ? ORDER BY id`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scrape results: %w", err)
+	}
+	defer rows.Close()
+
+	results := make(map[string][]models.ScrapeResult)
+	for rows.Next() {
+		var result models.ScrapeResult
+		if err := scanJSON(rows, &result); err != nil {
+			return nil, err
+		}
+		results[result.SiteID] = append(results[result.SiteID], result)
+	}
+	return results, rows.Err()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// scanJSON decodes the single JSON column of the current row into v
+func scanJSON(rows *sql.Rows, v interface{}) error {
+	var data string
+	if err := rows.Scan(&data); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("failed to decode stored record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"betting-odds-scraper/internal/config"
+	"betting-odds-scraper/internal/models"
+)
+
+// Store persists matches, odds and scrape results for the scraper manager
+type Store interface {
+	// SaveMatches inserts or updates canonical matches
+	SaveMatches(matches []models.Match) error
+	// SaveOdds replaces a site's current odds and keeps them as a snapshot
+	SaveOdds(siteID string, odds []models.Odds) error
+	// SaveScrapeResult records the outcome of one site scrape
+	SaveScrapeResult(result models.ScrapeResult) error
+	// SavePriceHistory appends price changes to each selection's time series
+	SavePriceHistory(points []models.PricePoint) error
+	// DeleteMatchesBefore removes matches that kicked off before the given
+	// time, together with their price history
+	DeleteMatchesBefore(before time.Time) (int, error)
+
+	// Matches returns every stored match keyed by ID
+	Matches() (map[string]models.Match, error)
+	// CurrentOdds returns the latest odds of every site keyed by site ID
+	CurrentOdds() (map[string][]models.Odds, error)
+	// PriceHistory returns every recorded price change for a match, oldest first
+	PriceHistory(matchID string) ([]models.PricePoint, error)
+	// ScrapeResults returns up to limit most recent results per site, oldest first
+	ScrapeResults(limit int) (map[string][]models.ScrapeResult, error)
+
+	Close() error
+}
+
+// Store drivers
+const (
+	DriverMemory = "memory"
+	DriverSQLite = "sqlite"
+)
+
+// New opens the store selected by the configuration
+func New(cfg *config.Config) (Store, error) {
+	switch cfg.StoreDriver {
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	case DriverSQLite:
+		return NewSQLiteStore(cfg.DatabasePath)
+	}
+	return nil, fmt.Errorf("unknown store driver %q", cfg.StoreDriver)
+}
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"betting-odds-scraper/internal/models"
+)
+
+// eachStore runs a test against every store driver
+func eachStore(t *testing.T, test func(t *testing.T, s Store)) {
+	t.Run(DriverMemory, func(t *testing.T) {
+		test(t, NewMemoryStore())
+	})
+	t.Run(DriverSQLite, func(t *testing.T) {
+		s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "odds.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		test(t, s)
+	})
+}
+
+var kickoff = time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+
+func testMatch(id string, matchTime time.Time) models.Match {
+	return models.Match{
+		ID:         id,
+		HomeTeam:   "Arsenal",
+		AwayTeam:   "Chelsea",
+		Sport:      "football",
+		League:     "Premier League",
+		MatchTime:  matchTime,
+		Status:     "upcoming",
+		SourceIDs:  map[string]string{"betika": "betika_1"},
+		Provenance: models.ProvenanceLive,
+	}
+}
+
+func testOdds(siteID, matchID string) models.Odds {
+	odd := models.Odds{
+		ID:            siteID + "_" + matchID + "_odds",
+		MatchID:       matchID,
+		SiteID:        siteID,
+		SiteName:      siteID,
+		SourceMatchID: siteID + "_1",
+		Provenance:    models.ProvenanceAPI,
+		ScrapedAt:     kickoff.Add(-time.Hour),
+	}
+	odd.SetMatchResult(2.1, 3.4, 3.6)
+	odd.SetGoalMarkets(1.85, 1.95, 0, 0)
+	odd.SyncMarkets()
+	return odd
+}
+
+func TestMatchesRoundTrip(t *testing.T) {
+	eachStore(t, func(t *testing.T, s Store) {
+		match := testMatch("arsenal_chelsea", kickoff)
+		if err := s.SaveMatches([]models.Match{match}); err != nil {
+			t.Fatal(err)
+		}
+		// Saving a match again updates it
+		match.Status = "live"
+		if err := s.SaveMatches([]models.Match{match}); err != nil {
+			t.Fatal(err)
+		}
+
+		matches, err := s.Matches()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != 1 || !reflect.DeepEqual(matches[match.ID], match) {
+			t.Errorf("got %+v, want %+v", matches, match)
+		}
+	})
+}
+
+func TestOddsRoundTrip(t *testing.T) {
+	eachStore(t, func(t *testing.T, s Store) {
+		first := testOdds("betika", "arsenal_chelsea")
+		second := testOdds("betika", "liverpool_everton")
+		other := testOdds("sportpesa", "arsenal_chelsea")
+		if err := s.SaveOdds("betika", []models.Odds{first, second}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveOdds("sportpesa", []models.Odds{other}); err != nil {
+			t.Fatal(err)
+		}
+		// A site's odds replace its previous odds and leave other sites alone
+		if err := s.SaveOdds("betika", []models.Odds{first}); err != nil {
+			t.Fatal(err)
+		}
+
+		odds, err := s.CurrentOdds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string][]models.Odds{
+			"betika":    {first},
+			"sportpesa": {other},
+		}
+		if !reflect.DeepEqual(odds, want) {
+			t.Errorf("got %+v, want %+v", odds, want)
+		}
+	})
+}
+
+func TestScrapeResults(t *testing.T) {
+	eachStore(t, func(t *testing.T, s Store) {
+		for i := 0; i < 5; i++ {
+			for _, siteID := range []string{"betika", "sportpesa"} {
+				result := models.ScrapeResult{
+					SiteID:     siteID,
+					Success:    true,
+					Status:     models.ScrapeOK,
+					Attempts:   1,
+					MatchCount: i,
+					Checks:     &models.ScrapeChecks{Events: i},
+					Duration:   time.Second,
+					ScrapedAt:  kickoff.Add(time.Duration(i) * time.Minute),
+				}
+				if err := s.SaveScrapeResult(result); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		// The most recent results of each site come back, oldest first
+		results, err := s.ScrapeResults(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, siteID := range []string{"betika", "sportpesa"} {
+			siteResults := results[siteID]
+			if len(siteResults) != 3 {
+				t.Fatalf("%s: got %d results, want 3", siteID, len(siteResults))
+			}
+			for i, result := range siteResults {
+				if result.MatchCount != i+2 || result.Checks == nil || result.Checks.Events != i+2 || !result.ScrapedAt.Equal(kickoff.Add(time.Duration(i+2)*time.Minute)) {
+					t.Errorf("%s: result %d is %+v", siteID, i, result)
+				}
+			}
+		}
+	})
+}
+
+func TestDeleteMatchesBefore(t *testing.T) {
+	eachStore(t, func(t *testing.T, s Store) {
+		past := testMatch("past", kickoff.Add(-24*time.Hour))
+		upcoming := testMatch("upcoming", kickoff)
+		if err := s.SaveMatches([]models.Match{past, upcoming}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveOdds("betika", []models.Odds{testOdds("betika", "past"), testOdds("betika", "upcoming")}); err != nil {
+			t.Fatal(err)
+		}
+		var points []models.PricePoint
+		for _, matchID := range []string{"past", "upcoming"} {
+			points = append(points, models.PricePoint{
+				MatchID:    matchID,
+				SiteID:     "betika",
+				Market:     models.MarketMatchResult,
+				Period:     models.PeriodFullTime,
+				Selection:  models.SelectionHome,
+				Value:      2.1,
+				RecordedAt: kickoff.Add(-time.Hour),
+			})
+		}
+		if err := s.SavePriceHistory(points); err != nil {
+			t.Fatal(err)
+		}
+
+		removed, err := s.DeleteMatchesBefore(kickoff.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if removed != 1 {
+			t.Errorf("removed %d matches, want 1", removed)
+		}
+
+		// The match goes with its odds and price history
+		matches, err := s.Matches()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, exists := matches["past"]; exists || len(matches) != 1 {
+			t.Errorf("matches left: %+v", matches)
+		}
+		odds, err := s.CurrentOdds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(odds["betika"]) != 1 || odds["betika"][0].MatchID != "upcoming" {
+			t.Errorf("odds left: %+v", odds)
+		}
+		history, err := s.PriceHistory("past")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 0 {
+			t.Errorf("price history left for the deleted match: %+v", history)
+		}
+		history, err = s.PriceHistory("upcoming")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 || history[0].Value != 2.1 || !history[0].RecordedAt.Equal(kickoff.Add(-time.Hour)) {
+			t.Errorf("price history of the upcoming match: %+v", history)
+		}
+	})
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"betting-odds-scraper/internal/api"
 	"betting-odds-scraper/internal/config"
-	"betting-odds-scraper/internal/scraper"
 	"betting-odds-scraper/internal/scheduler"
+	"betting-odds-scraper/internal/scraper"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +21,10 @@ func main() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// Stop on Ctrl-C or a container stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize configuration
 	cfg := config.New()
 
@@ -25,19 +32,32 @@ func main() {
 	scraperManager := scraper.NewManager(cfg)
 
 	// Initialize scheduler for periodic scraping
-	scheduler := scheduler.New(scraperManager)
+	scheduler := scheduler.New(scraperManager, cfg)
 	scheduler.Start()
 
 	// Initialize and start API server
-	server := api.NewServer(scraperManager)
-	
+	server := api.NewServer(scraperManager, scheduler)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
 	log.Printf("Starting server on port %s", port)
-	if err := server.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	err := server.Run(ctx, ":"+port)
+	if err != nil {
+		log.Printf("Server stopped: %v", err)
+	} else {
+		log.Println("Shutting down")
+	}
+
+	// Release browsers, proxies and the store before exiting, which a
+	// deferred call would not do once os.Exit runs
+	scheduler.Stop()
+	if closeErr := scraperManager.Close(); closeErr != nil {
+		log.Printf("Failed to close scraper manager: %v", closeErr)
+	}
+	if err != nil {
+		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
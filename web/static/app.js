@@ -160,7 +160,7 @@ function exportOddsData(format = 'json') {
 }
 
 function convertToCSV(data) {
-    const headers = ['Home Team', 'Away Team', 'League', 'Home Odds', 'Home Site', 'Draw Odds', 'Draw Site', 'Away Odds', 'Away Site', 'Updated'];
+    const headers = ['Home Team', 'Away Team', 'League', 'Home Odds', 'Home Site', 'Draw Odds', 'Draw Site', 'Away Odds', 'Away Site', 'Over 2.5', 'Under 2.5', 'BTTS Yes', 'BTTS No', 'Updated'];
     const rows = data.map(match => [
         match.match.home_team,
         match.match.away_team,
@@ -171,6 +171,10 @@ function convertToCSV(data) {
         match.best_draw?.site_name || '',
         match.best_away_win?.value || '',
         match.best_away_win?.site_name || '',
+        match.best_over_2_5?.value || '',
+        match.best_under_2_5?.value || '',
+        match.best_btts?.value || '',
+        match.best_btts_no?.value || '',
         new Date(match.updated_at).toLocaleString()
     ]);
     